@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"net"
-	"strconv"
 	"sync"
 	"time"
 
@@ -17,6 +16,8 @@ const (
 	wsPort                 = 3000
 	registerTimeoutSeconds = 60
 	requestTimeoutSeconds  = 10
+	respChanBuffer         = 8
+	subChanBuffer          = 64
 )
 
 var (
@@ -33,61 +34,53 @@ var (
 	ErrRequestTimeout = errors.New("Timeout waiting for response to request")
 	// ErrConnectionTimeout is returned when we fail to open the websocket connection before the timeout
 	ErrConnectionTimeout = errors.New("Failed to connect to TV's websocket connection before timeout")
+	// ErrConnectionClosed is returned when the connection to the TV is closed or lost while waiting
+	// for a response
+	ErrConnectionClosed = errors.New("Connection to TV was closed")
 )
 
 // Connection represents a web socket connection to the TV
 type Connection struct {
 	conn          *websocket.Conn
-	connOpen      *bool
+	done          chan struct{}
+	closeOnce     sync.Once
+	writeLock     sync.Mutex
 	idLock        sync.Mutex
 	lastRequestID int
+	respLock      sync.Mutex
 	respChans     map[int]chan response
-	respPayloads  map[int]interface{}
 }
 
 // NewConnection creates a new web socket connection to the TV at the given IP address. The timeout is in milliseconds.
 func NewConnection(ip net.IP, timeout int) (*Connection, error) {
 	url := fmt.Sprintf("ws://%v:%v", ip, wsPort)
 
-	// Dial the websocket connection, with a timeout
-	conChan := make(chan *websocket.Conn)
-	errChan := make(chan error)
-	go func(url string) {
-		c, _, err := websocket.DefaultDialer.Dial(url, nil)
-		if err != nil {
-			errChan <- err
-			return
-		}
-
-		conChan <- c
-	}(url)
-
-	ticker := time.NewTicker(time.Duration(timeout) * time.Millisecond)
-	select {
-	case <-ticker.C:
-		return nil, ErrConnectionTimeout
-	case err := <-errChan:
+	c, err := dial(url, timeout)
+	if err != nil {
 		return nil, err
-	case c := <-conChan:
-		// Set the routine going to get responses
-		connOpen := true
-		connection := &Connection{c,
-			&connOpen,
-			sync.Mutex{},
-			0,
-			make(map[int]chan response),
-			make(map[int]interface{}),
-		}
-
-		go connection.respWorker()
+	}
 
-		return connection, nil
+	// Set the routine going to get responses
+	connection := &Connection{
+		conn:      c,
+		done:      make(chan struct{}),
+		respChans: make(map[int]chan response),
 	}
+
+	go connection.respWorker()
+
+	return connection, nil
 }
 
 // Register registers with the TV using the provided client key.
 // If no client key is provided, the TV will generate a new one
 func (c *Connection) Register(clientKey string) (string, error) {
+	return c.RegisterWithPrompt(clientKey, nil)
+}
+
+// RegisterWithPrompt registers with the TV in the same way as Register. If the TV prompts
+// the user to accept the connection, onPrompt is called before waiting for them to do so.
+func (c *Connection) RegisterWithPrompt(clientKey string, onPrompt func()) (string, error) {
 	// Create the request
 	requestID := c.getID()
 	request := request{
@@ -109,11 +102,11 @@ func (c *Connection) Register(clientKey string) (string, error) {
 	}
 
 	// Create the channel to recieve the response
-	respChan := c.addRespChannel(requestID)
+	respChan := c.addRespChannel(requestID, respChanBuffer)
 	defer c.removeRespChan(requestID)
 
 	// Send the message to the websocket
-	err = c.conn.WriteMessage(websocket.TextMessage, message)
+	err = c.write(message)
 	if err != nil {
 		return "", err
 	}
@@ -121,15 +114,27 @@ func (c *Connection) Register(clientKey string) (string, error) {
 	// For register requests, multiple responses are recieved, wait until we get either
 	// an error response, or a registered response (or we timeout)
 	ticker := time.NewTicker(registerTimeoutSeconds * time.Second)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case <-ticker.C:
 			return "", ErrRegisterTimeout
+		case <-c.done:
+			return "", ErrConnectionClosed
 		case resp := <-respChan:
 			if resp.Type == respTypeRegistered {
-				return resp.Payload.(*registerRespPayload).ClientKey, nil
+				var payload registerRespPayload
+				err = json.Unmarshal(resp.Payload, &payload)
+				return payload.ClientKey, err
 			} else if resp.Type == respTypeError {
 				return "", errors.New(resp.Error)
+			} else if resp.Type == respTypeResponse && onPrompt != nil {
+				var payload registerPromptPayload
+				err = json.Unmarshal(resp.Payload, &payload)
+				if err == nil && payload.PairingType == pairTypePrompt {
+					onPrompt()
+				}
 			}
 		}
 	}
@@ -156,17 +161,11 @@ func (c *Connection) Request(uri string, reqPayload interface{}, respPayload int
 	}
 
 	// Create the channel to recieve the response
-	respChan := c.addRespChannel(requestID)
+	respChan := c.addRespChannel(requestID, respChanBuffer)
 	defer c.removeRespChan(requestID)
 
-	// Add the response payload to the map of response payloads if one has been provided
-	if respPayload != nil {
-		c.respPayloads[requestID] = respPayload
-		defer delete(c.respPayloads, requestID)
-	}
-
 	// Send the message to the websocket
-	err = c.conn.WriteMessage(websocket.TextMessage, message)
+	err = c.write(message)
 	if err != nil {
 		return err
 	}
@@ -178,68 +177,154 @@ func (c *Connection) Request(uri string, reqPayload interface{}, respPayload int
 	select {
 	case <-ticker.C:
 		return ErrRequestTimeout
+	case <-c.done:
+		return ErrConnectionClosed
 	case resp := <-respChan:
-		if resp.Error != "" {
-			return errors.New(resp.Error)
+		return decodeResponse(resp, respPayload)
+	}
+}
+
+// RequestBatch makes several requests to the TV at once. All of the requests are sent
+// back-to-back before waiting for any responses, which are then matched up to their
+// requests by ID. The returned slice holds the error for each request, in the same order
+// as the requests were provided.
+func (c *Connection) RequestBatch(requests []BatchRequest) []error {
+	errs := make([]error, len(requests))
+	respChans := make([]chan response, len(requests))
+
+	// Send all the requests without waiting for responses
+	for i, v := range requests {
+		requestID := c.getID()
+		request := request{
+			ID:   requestID,
+			Type: reqTypeRequest,
+			URI:  v.URI,
+		}
+
+		if v.Payload != nil {
+			request.Payload = v.Payload
+		}
+
+		message, err := json.Marshal(request)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+
+		respChan := c.addRespChannel(requestID, respChanBuffer)
+		defer c.removeRespChan(requestID)
+
+		err = c.write(message)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+
+		respChans[i] = respChan
+	}
+
+	// Wait for the responses. The timeout applies to the batch as a whole.
+	ticker := time.NewTicker(requestTimeoutSeconds * time.Second)
+	defer ticker.Stop()
+
+	timedOut := false
+	for i, v := range respChans {
+		if v == nil {
+			continue
+		}
+
+		if timedOut {
+			errs[i] = ErrRequestTimeout
+			continue
+		}
+
+		select {
+		case <-ticker.C:
+			timedOut = true
+			errs[i] = ErrRequestTimeout
+		case <-c.done:
+			errs[i] = ErrConnectionClosed
+		case resp := <-v:
+			errs[i] = decodeResponse(resp, requests[i].Response)
 		}
 	}
 
-	return nil
+	return errs
 }
 
 // Close closes the connection to the TV
 func (c *Connection) Close() error {
-	*c.connOpen = false
+	c.markClosed()
 	return c.conn.Close()
 }
 
-func (c *Connection) addRespChannel(reqID int) chan response {
-	respChan := make(chan response)
+// Done returns a channel which is closed once the connection to the TV has been closed,
+// either by calling Close, or because the connection was lost
+func (c *Connection) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *Connection) markClosed() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+func (c *Connection) addRespChannel(reqID int, size int) chan response {
+	c.respLock.Lock()
+	defer c.respLock.Unlock()
+
+	respChan := make(chan response, size)
 	c.respChans[reqID] = respChan
 
 	return respChan
 }
 
 func (c *Connection) removeRespChan(reqID int) {
-	if val, ok := c.respChans[reqID]; ok {
-		close(val)
-		delete(c.respChans, reqID)
-	}
+	c.respLock.Lock()
+	defer c.respLock.Unlock()
+
+	delete(c.respChans, reqID)
 }
 
 func (c *Connection) respWorker() {
-	for *c.connOpen {
-		// Read a message from the connection
+	for {
+		// Read a message from the connection. Once reading fails, the connection
+		// is no longer usable, so it's marked as closed.
 		_, message, err := c.conn.ReadMessage()
 		if err != nil {
-			continue
-		}
-
-		// Get the ID of the response
-		respID, err := getRespID(message)
-		if err != nil {
-			continue
+			c.markClosed()
+			return
 		}
 
-		// Unmarshal the response
-		var payload interface{}
-		if val, ok := c.respPayloads[respID]; ok {
-			payload = val
-		} else {
-			payload = &responsePayload{}
-		}
-		resp, err := unmarshalResponse(message, payload)
+		// Decode the envelope of the response only. The payload is left as raw JSON,
+		// and is decoded in to its target type by whoever is waiting for the response.
+		var resp response
+		err = json.Unmarshal(message, &resp)
 		if err != nil {
 			continue
 		}
 
-		// Send the response to the appropriate channel
+		// Send the response to the appropriate channel. If nobody is waiting for
+		// the response any more, or they aren't keeping up, it's dropped.
+		c.respLock.Lock()
 		if val, ok := c.respChans[resp.ID]; ok {
-			val <- resp
+			select {
+			case val <- resp:
+			default:
+			}
 		}
+		c.respLock.Unlock()
 	}
 }
 
+func (c *Connection) write(message []byte) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	return c.conn.WriteMessage(websocket.TextMessage, message)
+}
+
 func (c *Connection) getID() int {
 	c.idLock.Lock()
 	defer c.idLock.Unlock()
@@ -248,47 +333,58 @@ func (c *Connection) getID() int {
 	return c.lastRequestID
 }
 
-func unmarshalResponse(message []byte, respPayload interface{}) (response, error) {
-	// Unmarshal the common properties first
-	var resp response
-	err := json.Unmarshal(message, &resp)
-	if err != nil {
-		return resp, err
-	}
+// dial opens a websocket connection to the provided URL. The timeout is in milliseconds.
+func dial(url string, timeout int) (*websocket.Conn, error) {
+	// Dial the websocket connection, with a timeout
+	conChan := make(chan *websocket.Conn)
+	errChan := make(chan error)
+	go func(url string) {
+		c, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			errChan <- err
+			return
+		}
 
-	// Use json.RawMessage to extract the JSON for the payload
-	var propertyMap map[string]*json.RawMessage
-	err = json.Unmarshal(message, &propertyMap)
-	if err != nil {
-		return resp, err
-	}
+		conChan <- c
+	}(url)
 
-	// Unmarshal the payload based on the type of the response
-	var payload interface{}
-	if resp.Type == respTypeResponse {
-		// If this is a "response" type, then use the provided payload pointer
-		payload = respPayload
-	} else if resp.Type == respTypeRegistered {
-		payload = &registerRespPayload{}
-	} else if resp.Type == respTypeError {
-		payload = &emptyPayload{}
-	} else {
-		return response{}, errUnknownResponseType
-	}
+	ticker := time.NewTicker(time.Duration(timeout) * time.Millisecond)
+	defer ticker.Stop()
 
-	err = json.Unmarshal(*propertyMap["payload"], payload)
-	resp.Payload = payload
-	return resp, err
+	select {
+	case <-ticker.C:
+		// Make sure the connection is closed if it does eventually open
+		go func() {
+			select {
+			case c := <-conChan:
+				c.Close()
+			case <-errChan:
+			}
+		}()
+		return nil, ErrConnectionTimeout
+	case err := <-errChan:
+		return nil, err
+	case c := <-conChan:
+		return c, nil
+	}
 }
 
-func getRespID(message []byte) (int, error) {
-	var propertyMap map[string]*json.RawMessage
-	err := json.Unmarshal(message, &propertyMap)
-	if err != nil {
-		return 0, err
-	}
+func decodeResponse(resp response, respPayload interface{}) error {
+	switch resp.Type {
+	case respTypeError:
+		return errors.New(resp.Error)
+	case respTypeResponse:
+		if resp.Error != "" {
+			return errors.New(resp.Error)
+		}
+		if respPayload == nil || len(resp.Payload) == 0 {
+			return nil
+		}
 
-	return strconv.Atoi(string(*propertyMap["id"]))
+		return json.Unmarshal(resp.Payload, respPayload)
+	default:
+		return errUnknownResponseType
+	}
 }
 
 func getPermissions() []string {
@@ -303,5 +399,11 @@ func getPermissions() []string {
 		permissionReadRunningApps,
 		permissionReadInstalledApps,
 		permissionReadInputList,
+		permissionControlInputJoystick,
+		permissionControlInputText,
+		permissionWriteToast,
+		permissionReadSettings,
+		permissionReadUpdateInfo,
+		permissionReadPowerState,
 	}
 }
@@ -0,0 +1,187 @@
+package connection
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+const (
+	fixtureChannelCount     = 5000
+	fixtureExtraFieldsCount = 12
+)
+
+// legacyResponse is the response envelope used before payloads were decoded lazily, which decoded
+// the payload in to a generic value along with the rest of the envelope
+type legacyResponse struct {
+	ID      int         `json:"id"`
+	Type    string      `json:"type"`
+	Error   string      `json:"error"`
+	Payload interface{} `json:"payload"`
+}
+
+// legacyDecode decodes a response in the way it was done before payloads were decoded lazily: once
+// to find the ID, once for the envelope, once to find the payload, and once more for the payload itself
+func legacyDecode(message []byte, respPayload interface{}) error {
+	var idMap map[string]*json.RawMessage
+	err := json.Unmarshal(message, &idMap)
+	if err != nil {
+		return err
+	}
+	_, err = strconv.Atoi(string(*idMap["id"]))
+	if err != nil {
+		return err
+	}
+
+	var resp legacyResponse
+	err = json.Unmarshal(message, &resp)
+	if err != nil {
+		return err
+	}
+
+	var propertyMap map[string]*json.RawMessage
+	err = json.Unmarshal(message, &propertyMap)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(*propertyMap["payload"], respPayload)
+}
+
+// newDecode decodes a response in the way respWorker and Request do now
+func newDecode(message []byte, respPayload interface{}) error {
+	var resp response
+	err := json.Unmarshal(message, &resp)
+	if err != nil {
+		return err
+	}
+
+	return decodeResponse(resp, respPayload)
+}
+
+// channelListFixture returns a channel list response with thousands of channels, each of which has
+// around 80 fields, like those returned by TVs with satellite tuners
+func channelListFixture(tb testing.TB) []byte {
+	channels := make([]map[string]interface{}, fixtureChannelCount)
+	for i := range channels {
+		channel := Channel{
+			ChannelID:     fmt.Sprintf("7_%v_%v_0_1_1_0", i, i),
+			ChannelNumber: strconv.Itoa(i + 1),
+			ChannelName:   fmt.Sprintf("Channel %v", i+1),
+			ChannelType:   "Satellite Digital TV",
+			FavoriteGroup: []string{"A"},
+			GroupIDList:   []int{1, 2},
+			HDTV:          i%2 == 0,
+			TV:            true,
+			DTV:           true,
+			Frequency:     11000 + i,
+			LastUpdated:   "2017,10,1,12,0,0",
+		}
+
+		data, err := json.Marshal(channel)
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		var fields map[string]interface{}
+		err = json.Unmarshal(data, &fields)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		for j := 0; j < fixtureExtraFieldsCount; j++ {
+			fields[fmt.Sprintf("unknownField%v", j)] = fmt.Sprintf("value %v", j)
+		}
+
+		channels[i] = fields
+	}
+
+	message, err := json.Marshal(map[string]interface{}{
+		"id":   1,
+		"type": respTypeResponse,
+		"payload": map[string]interface{}{
+			"returnValue":      true,
+			"channelListCount": fixtureChannelCount,
+			"channelList":      channels,
+		},
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return message
+}
+
+func TestDecodeResponse(t *testing.T) {
+	message := channelListFixture(t)
+
+	var legacyPayload, newPayload GetChannelListResponsePayload
+	if err := legacyDecode(message, &legacyPayload); err != nil {
+		t.Fatal(err)
+	}
+	if err := newDecode(message, &newPayload); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(newPayload.ChannelList) != fixtureChannelCount {
+		t.Fatalf("Expected %v channels, got %v", fixtureChannelCount, len(newPayload.ChannelList))
+	}
+	if newPayload.ChannelList[41].ChannelName != legacyPayload.ChannelList[41].ChannelName {
+		t.Errorf("Expected channel name %q, got %q", legacyPayload.ChannelList[41].ChannelName,
+			newPayload.ChannelList[41].ChannelName)
+	}
+}
+
+func TestDecodeResponseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    response
+		wantErr string
+	}{
+		{"error response", response{Type: respTypeError, Error: "404 no such service or method"}, "404 no such service or method"},
+		{"response with error", response{Type: respTypeResponse, Error: "500 failed"}, "500 failed"},
+		{"unknown type", response{Type: "unknown"}, errUnknownResponseType.Error()},
+		{"empty payload", response{Type: respTypeResponse}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload GetVolumeResponsePayload
+			err := decodeResponse(tt.resp, &payload)
+
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tt.wantErr {
+				t.Errorf("Expected error %q, got %q", tt.wantErr, got)
+			}
+		})
+	}
+}
+
+func BenchmarkDecodeChannelListLegacy(b *testing.B) {
+	message := channelListFixture(b)
+	b.SetBytes(int64(len(message)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var payload GetChannelListResponsePayload
+		if err := legacyDecode(message, &payload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeChannelList(b *testing.B) {
+	message := channelListFixture(b)
+	b.SetBytes(int64(len(message)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var payload GetChannelListResponsePayload
+		if err := newDecode(message, &payload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
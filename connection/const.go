@@ -2,8 +2,10 @@ package connection
 
 const (
 	// Request types
-	reqTypeRegister = "register"
-	reqTypeRequest  = "request"
+	reqTypeRegister    = "register"
+	reqTypeRequest     = "request"
+	reqTypeSubscribe   = "subscribe"
+	reqTypeUnsubscribe = "unsubscribe"
 
 	// Response types
 	respTypeRegistered = "registered"
@@ -14,14 +16,20 @@ const (
 	pairTypePrompt = "PROMPT"
 
 	// Permissions
-	permissionLaunch             = "LAUNCH"
-	permissionControlAudio       = "CONTROL_AUDIO"
-	permissionControlPower       = "CONTROL_POWER"
-	permissionControlInputTv     = "CONTROL_INPUT_TV"
-	permissionControlPlayback    = "CONTROL_INPUT_MEDIA_PLAYBACK"
-	permissionReadChannelList    = "READ_TV_CHANNEL_LIST"
-	permissionReadCurrentChannel = "READ_CURRENT_CHANNEL"
-	permissionReadRunningApps    = "READ_RUNNING_APPS"
-	permissionReadInstalledApps  = "READ_INSTALLED_APPS"
-	permissionReadInputList      = "READ_INPUT_DEVICE_LIST"
+	permissionLaunch               = "LAUNCH"
+	permissionControlAudio         = "CONTROL_AUDIO"
+	permissionControlPower         = "CONTROL_POWER"
+	permissionControlInputTv       = "CONTROL_INPUT_TV"
+	permissionControlPlayback      = "CONTROL_INPUT_MEDIA_PLAYBACK"
+	permissionReadChannelList      = "READ_TV_CHANNEL_LIST"
+	permissionReadCurrentChannel   = "READ_CURRENT_CHANNEL"
+	permissionReadRunningApps      = "READ_RUNNING_APPS"
+	permissionReadInstalledApps    = "READ_INSTALLED_APPS"
+	permissionReadInputList        = "READ_INPUT_DEVICE_LIST"
+	permissionControlInputJoystick = "CONTROL_INPUT_JOYSTICK"
+	permissionControlInputText     = "CONTROL_INPUT_TEXT"
+	permissionWriteToast           = "WRITE_NOTIFICATION_TOAST"
+	permissionReadSettings         = "READ_SETTINGS"
+	permissionReadUpdateInfo       = "READ_UPDATE_INFO"
+	permissionReadPowerState       = "READ_POWER_STATE"
 )
@@ -0,0 +1,65 @@
+package connection
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// PointerSocket represents the TV's secondary web socket connection, which is used to send
+// remote control button presses and pointer input
+type PointerSocket struct {
+	conn      *websocket.Conn
+	writeLock sync.Mutex
+}
+
+// NewPointerSocket opens the pointer input socket at the provided URL, as returned by the
+// TV's getPointerInputSocket request. The timeout is in milliseconds.
+func NewPointerSocket(url string, timeout int) (*PointerSocket, error) {
+	c, err := dial(url, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PointerSocket{
+		conn: c,
+	}, nil
+}
+
+// SendButton sends a press of the remote control button with the provided name
+func (p *PointerSocket) SendButton(name string) error {
+	return p.send(fmt.Sprintf("type:button\nname:%v\n\n", name))
+}
+
+// SendMove moves the pointer by the provided number of pixels. If drag is true, the
+// pointer is moved with the button held down.
+func (p *PointerSocket) SendMove(dx, dy int, drag bool) error {
+	down := 0
+	if drag {
+		down = 1
+	}
+	return p.send(fmt.Sprintf("type:move\ndx:%v\ndy:%v\ndown:%v\n\n", dx, dy, down))
+}
+
+// SendClick clicks at the current position of the pointer
+func (p *PointerSocket) SendClick() error {
+	return p.send("type:click\n\n")
+}
+
+// SendScroll scrolls by the provided amount
+func (p *PointerSocket) SendScroll(dx, dy int) error {
+	return p.send(fmt.Sprintf("type:scroll\ndx:%v\ndy:%v\n\n", dx, dy))
+}
+
+// Close closes the pointer input socket
+func (p *PointerSocket) Close() error {
+	return p.conn.Close()
+}
+
+func (p *PointerSocket) send(message string) error {
+	p.writeLock.Lock()
+	defer p.writeLock.Unlock()
+
+	return p.conn.WriteMessage(websocket.TextMessage, []byte(message))
+}
@@ -15,8 +15,12 @@ type registerReqPayload struct {
 	ClientKey   string   `json:"client-key"`
 }
 
-// Represents a payload which doesn't hold any data
-type emptyPayload struct {
+// BatchRequest is a single request made to the TV as part of a batch. If Response is
+// provided, the payload of the response is unmarshalled in to it.
+type BatchRequest struct {
+	URI      string
+	Payload  interface{}
+	Response interface{}
 }
 
 // Manifest represent an optional manifest sent in the payload
@@ -47,6 +51,91 @@ type SwitchInputPayload struct {
 // LaunchAppPayload is the payload send with a "LaunchApp" request
 type LaunchAppPayload struct {
 	ID         string      `json:"id"`
-	ContentID  string      `json:"contentId"`
-	Parameters interface{} `json:"params"`
+	ContentID  string      `json:"contentId,omitempty"`
+	Parameters interface{} `json:"params,omitempty"`
+}
+
+// InsertTextPayload is the payload sent with an InsertText request
+type InsertTextPayload struct {
+	Text    string `json:"text"`
+	Replace bool   `json:"replace"`
+}
+
+// DeleteCharactersPayload is the payload sent with a DeleteCharacters request
+type DeleteCharactersPayload struct {
+	Count int `json:"count"`
+}
+
+// CloseAppPayload is the payload sent with a "CloseApp" request. Either the ID of the app,
+// or the ID of the session to close should be set.
+type CloseAppPayload struct {
+	ID        string `json:"id,omitempty"`
+	SessionID string `json:"sessionId,omitempty"`
+}
+
+// GetAppStatePayload is the payload sent with a "GetAppState" request
+type GetAppStatePayload struct {
+	ID string `json:"id"`
+}
+
+// OpenPayload is the payload sent with an "Open" request
+type OpenPayload struct {
+	Target string `json:"target"`
+}
+
+// CreateToastPayload is the payload sent with a CreateToast request
+type CreateToastPayload struct {
+	Message       string       `json:"message"`
+	IconData      string       `json:"iconData,omitempty"`
+	IconExtension string       `json:"iconExtension,omitempty"`
+	OnClick       *ToastAction `json:"onClick,omitempty"`
+}
+
+// ToastAction is the action taken when a toast is clicked
+type ToastAction struct {
+	AppID  string      `json:"appId"`
+	Params interface{} `json:"params,omitempty"`
+}
+
+// CreateAlertPayload is the payload sent with a CreateAlert request
+type CreateAlertPayload struct {
+	Message string        `json:"message"`
+	Buttons []AlertButton `json:"buttons"`
+	OnClose *AlertAction  `json:"onclose,omitempty"`
+}
+
+// AlertButton is a button shown on an alert
+type AlertButton struct {
+	Label   string      `json:"label"`
+	OnClick string      `json:"onClick,omitempty"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// AlertAction is an action taken by an alert
+type AlertAction struct {
+	URI    string      `json:"uri"`
+	Params interface{} `json:"params,omitempty"`
+}
+
+// CloseAlertPayload is the payload sent with a CloseAlert request
+type CloseAlertPayload struct {
+	AlertID string `json:"alertId"`
+}
+
+// GetSystemSettingsPayload is the payload sent with a GetSystemSettings request
+type GetSystemSettingsPayload struct {
+	Category string   `json:"category"`
+	Keys     []string `json:"keys"`
+}
+
+// SetSystemSettingsPayload is the payload sent with a SetSystemSettings request
+type SetSystemSettingsPayload struct {
+	Category string                 `json:"category"`
+	Settings map[string]interface{} `json:"settings"`
+}
+
+// DeleteSystemSettingsPayload is the payload sent with a DeleteSystemSettings request
+type DeleteSystemSettingsPayload struct {
+	Category string   `json:"category"`
+	Keys     []string `json:"keys"`
 }
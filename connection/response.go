@@ -6,10 +6,10 @@ import (
 
 // Represents a response from the Web OS made to a request
 type response struct {
-	ID      int         `json:"id"`
-	Type    string      `json:"type"`
-	Error   string      `json:"error"`
-	Payload interface{} `json:"payload"`
+	ID      int             `json:"id"`
+	Type    string          `json:"type"`
+	Error   string          `json:"error"`
+	Payload json.RawMessage `json:"payload"`
 }
 
 // Represents a "registered" response payload to a request to register
@@ -17,9 +17,11 @@ type registerRespPayload struct {
 	ClientKey string `json:"client-key"`
 }
 
-// Represents a response payload to a request to register
-type responsePayload struct {
-	ReturnValue bool `json:"returnValue"`
+// Represents a "response" response payload to a request to register, sent when the TV
+// prompts the user to accept the connection
+type registerPromptPayload struct {
+	PairingType string `json:"pairingType"`
+	ReturnValue bool   `json:"returnValue"`
 }
 
 // GetVolumeResponsePayload is the payload returned to "GetVolume" requests
@@ -97,6 +99,152 @@ type LaunchAppResponsePayload struct {
 	SessionID   string `json:"sessionId"`
 }
 
+// GetPointerInputSocketResponsePayload is the payload returned to "GetPointerInputSocket" requests
+type GetPointerInputSocketResponsePayload struct {
+	ReturnValue bool   `json:"returnValue"`
+	SocketPath  string `json:"socketPath"`
+}
+
+// RegisterRemoteKeyboardResponsePayload is the payload returned to "RegisterRemoteKeyboard" subscriptions
+type RegisterRemoteKeyboardResponsePayload struct {
+	ReturnValue   bool           `json:"returnValue"`
+	CurrentWidget KeyboardWidget `json:"currentWidget"`
+}
+
+// KeyboardWidget represents the text field on the TV which the remote keyboard is typing in to
+type KeyboardWidget struct {
+	Focus                 bool   `json:"focus"`
+	ContentType           string `json:"contentType"`
+	HiddenText            bool   `json:"hiddenText"`
+	PredictionEnabled     bool   `json:"predictionEnabled"`
+	CorrectionEnabled     bool   `json:"correctionEnabled"`
+	AutoCapitalization    bool   `json:"autoCapitalization"`
+	HasSurroundingText    bool   `json:"hasSurroundingText"`
+	SurroundingText       string `json:"surroundingText"`
+	SurroundingTextLength int    `json:"surroundingTextLength"`
+	CursorPosition        int    `json:"cursorPosition"`
+}
+
+// GetForegroundAppInfoResponsePayload is the payload returned to "GetForegroundAppInfo" requests
+type GetForegroundAppInfoResponsePayload struct {
+	ReturnValue bool   `json:"returnValue"`
+	AppID       string `json:"appId"`
+	WindowID    string `json:"windowId"`
+	ProcessID   string `json:"processId"`
+}
+
+// CreateToastResponsePayload is the payload returned to "CreateToast" requests
+type CreateToastResponsePayload struct {
+	ReturnValue bool   `json:"returnValue"`
+	ToastID     string `json:"toastId"`
+}
+
+// CreateAlertResponsePayload is the payload returned to "CreateAlert" requests
+type CreateAlertResponsePayload struct {
+	ReturnValue bool   `json:"returnValue"`
+	AlertID     string `json:"alertId"`
+}
+
+// GetSystemSettingsResponsePayload is the payload returned to "GetSystemSettings" requests
+type GetSystemSettingsResponsePayload struct {
+	ReturnValue bool                   `json:"returnValue"`
+	Category    string                 `json:"category"`
+	Settings    map[string]interface{} `json:"settings"`
+}
+
+// GetPowerStateResponsePayload is the payload returned to "GetPowerState" requests
+type GetPowerStateResponsePayload struct {
+	ReturnValue bool   `json:"returnValue"`
+	Subscribed  bool   `json:"subscribed"`
+	State       string `json:"state"`
+	Processing  string `json:"processing"`
+}
+
+// GetServiceListResponsePayload is the payload returned to "GetServiceList" requests
+type GetServiceListResponsePayload struct {
+	ReturnValue bool      `json:"returnValue"`
+	Services    []Service `json:"services"`
+}
+
+// Service represents a service provided by the TV
+type Service struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+}
+
+// GetSystemInfoResponsePayload is the payload returned to "GetSystemInfo" requests
+type GetSystemInfoResponsePayload struct {
+	ReturnValue  bool            `json:"returnValue"`
+	Features     map[string]bool `json:"features"`
+	ReceiverType string          `json:"receiverType"`
+	ModelName    string          `json:"modelName"`
+}
+
+// GetSoftwareInfoResponsePayload is the payload returned to "GetCurrentSWInformation" requests
+type GetSoftwareInfoResponsePayload struct {
+	ReturnValue  bool   `json:"returnValue"`
+	ProductName  string `json:"product_name"`
+	ModelName    string `json:"model_name"`
+	SWType       string `json:"sw_type"`
+	MajorVersion string `json:"major_ver"`
+	MinorVersion string `json:"minor_ver"`
+	Country      string `json:"country"`
+	CountryGroup string `json:"country_group"`
+	DeviceID     string `json:"device_id"`
+	LanguageCode string `json:"language_code"`
+}
+
+// GetAppStateResponsePayload is the payload returned to "GetAppState" requests
+type GetAppStateResponsePayload struct {
+	ReturnValue bool `json:"returnValue"`
+	Running     bool `json:"running"`
+	Visible     bool `json:"visible"`
+}
+
+// GetRunningAppsResponsePayload is the payload returned to "GetRunningApps" requests
+type GetRunningAppsResponsePayload struct {
+	ReturnValue bool         `json:"returnValue"`
+	Running     []RunningApp `json:"running"`
+}
+
+// RunningApp represents an app which is running on the TV
+type RunningApp struct {
+	ID                string `json:"id"`
+	ProcessID         string `json:"processid"`
+	WebProcessID      string `json:"webprocessid"`
+	DefaultWindowType string `json:"defaultWindowType"`
+}
+
+// ListLaunchPointsResponsePayload is the payload returned to "ListLaunchPoints" requests. When
+// subscribed, messages after the first describe a single change to a launch point, in which case
+// the launch point's details are included alongside the type of change.
+type ListLaunchPointsResponsePayload struct {
+	ReturnValue  bool          `json:"returnValue"`
+	Subscribed   bool          `json:"subscribed"`
+	LaunchPoints []LaunchPoint `json:"launchPoints"`
+	Change       string        `json:"change"`
+	Position     int           `json:"position"`
+	LaunchPoint
+}
+
+// LaunchPoint represents an entry on the TV's home screen ribbon
+type LaunchPoint struct {
+	ID              string                 `json:"id"`
+	LaunchPointID   string                 `json:"launchPointId"`
+	Title           string                 `json:"title"`
+	Icon            string                 `json:"icon"`
+	LargeIcon       string                 `json:"largeIcon"`
+	ImageForRecents string                 `json:"imageForRecents"`
+	BgColor         string                 `json:"bgColor"`
+	BgImage         string                 `json:"bgImage"`
+	IconColor       string                 `json:"iconColor"`
+	Params          map[string]interface{} `json:"params"`
+	Removable       bool                   `json:"removable"`
+	SystemApp       bool                   `json:"systemApp"`
+	Unmovable       bool                   `json:"unmovable"`
+	LaunchPointType string                 `json:"lptype"`
+}
+
 // GetExternalInputListResponsePayload is the payload returned to "ListExternalInputs" requests
 type GetExternalInputListResponsePayload struct {
 	ReturnValue bool     `json:"returnValue"`
@@ -0,0 +1,137 @@
+package connection
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrSubscriptionClosed is returned when waiting for a message from a subscription which
+// has been closed, either by calling Close, or because the connection to the TV was lost
+var ErrSubscriptionClosed = errors.New("Subscription is closed")
+
+// Subscription represents a subscription to changes on the TV. The TV sends a message with
+// the current state when subscribed, and then another each time it changes.
+type Subscription struct {
+	c         *Connection
+	id        int
+	respChan  chan response
+	first     *response
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Subscribe subscribes to the provided URI. It waits for the first message from the TV, so that
+// an error is returned straight away if the TV refuses the subscription.
+func (c *Connection) Subscribe(uri string, reqPayload interface{}) (*Subscription, error) {
+	// Create the request
+	requestID := c.getID()
+	request := request{
+		ID:   requestID,
+		Type: reqTypeSubscribe,
+		URI:  uri,
+	}
+
+	if reqPayload != nil {
+		request.Payload = reqPayload
+	}
+
+	// Marshal the request
+	message, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the channel to recieve the messages. It's larger than usual so that bursts
+	// of changes aren't dropped.
+	respChan := c.addRespChannel(requestID, subChanBuffer)
+
+	// Send the message to the websocket
+	err = c.write(message)
+	if err != nil {
+		c.removeRespChan(requestID)
+		return nil, err
+	}
+
+	// Wait for the first message (or timeout)
+	ticker := time.NewTicker(requestTimeoutSeconds * time.Second)
+	defer ticker.Stop()
+
+	var first response
+	select {
+	case <-ticker.C:
+		err = ErrRequestTimeout
+	case <-c.done:
+		err = ErrConnectionClosed
+	case first = <-respChan:
+		err = decodeResponse(first, nil)
+	}
+
+	if err != nil {
+		c.removeRespChan(requestID)
+		return nil, err
+	}
+
+	return &Subscription{
+		c:        c,
+		id:       requestID,
+		respChan: respChan,
+		first:    &first,
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// Next waits for the next message from the TV, and unmarshals its payload in to the provided
+// payload. The first call returns the message received when subscribing. Next must not be
+// called from more than one goroutine at once.
+func (s *Subscription) Next(respPayload interface{}) error {
+	if s.first != nil {
+		resp := *s.first
+		s.first = nil
+		return decodeResponse(resp, respPayload)
+	}
+
+	select {
+	case <-s.done:
+		return ErrSubscriptionClosed
+	case <-s.c.done:
+		return ErrSubscriptionClosed
+	case resp := <-s.respChan:
+		return decodeResponse(resp, respPayload)
+	}
+}
+
+// Done returns a channel which is closed once the subscription has been closed
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close unsubscribes from the TV. Any call to Next which is waiting returns ErrSubscriptionClosed.
+func (s *Subscription) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.c.removeRespChan(s.id)
+
+		request := request{
+			ID:   s.id,
+			Type: reqTypeUnsubscribe,
+		}
+
+		var message []byte
+		message, err = json.Marshal(request)
+		if err != nil {
+			return
+		}
+
+		// If the connection has already gone, there's nothing to unsubscribe from
+		select {
+		case <-s.c.done:
+		default:
+			err = s.c.write(message)
+		}
+	})
+
+	return err
+}
@@ -1,13 +1,132 @@
 package control
 
+import (
+	"context"
+	"time"
+)
+
+const browserAppID = "com.webos.app.browser"
+
 // App represents an app on the TV
 type App struct {
-	Name string
-	ID   string
-	tv   *LgTv
+	Name    string
+	ID      string
+	Version string
+	Vendor  string
+	// Category is the category the app is listed under on the TV, if it has one
+	Category string
+	// SystemApp is true for apps which are part of webOS itself, rather than installed from the store
+	SystemApp bool
+	// Visible is false for apps which aren't shown in the TV's launcher, such as background services
+	Visible   bool
+	Removable bool
+	// InstalledTime is when the app was installed, which is zero if the TV didn't say
+	InstalledTime time.Time
+	// Size is the size of the app in bytes
+	Size int64
+	// State is the running state of the app. It's only set for apps returned by ListRunningApps.
+	State   AppState
+	iconURL string
+	tv      Apps
+}
+
+// AppFilter decides whether an app is included when listing apps
+type AppFilter func(app App) bool
+
+// VisibleOnly is an app filter which only includes apps shown in the TV's launcher
+func VisibleOnly(app App) bool {
+	return app.Visible
+}
+
+// ExcludeSystemApps is an app filter which leaves out apps which are part of webOS itself
+func ExcludeSystemApps(app App) bool {
+	return !app.SystemApp
+}
+
+// filterApps returns the apps which are included by all of the provided filters
+func filterApps(apps []App, filters []AppFilter) []App {
+	if len(filters) == 0 {
+		return apps
+	}
+
+	filtered := apps[:0]
+	for _, v := range apps {
+		include := true
+		for _, filter := range filters {
+			if !filter(v) {
+				include = false
+				break
+			}
+		}
+
+		if include {
+			filtered = append(filtered, v)
+		}
+	}
+
+	return filtered
+}
+
+// AppState is the state of an app on the TV
+type AppState struct {
+	Running bool
+	Visible bool
+}
+
+// Background returns whether the app is running in the background
+func (s AppState) Background() bool {
+	return s.Running && !s.Visible
 }
 
-// Launch launches the app on the TV. It returns the ID of the new session
-func (app *App) Launch() (string, error) {
-	return app.tv.LaunchApp(app.ID)
+// LaunchOption is an option used when launching an app
+type LaunchOption func(*launchOptions)
+
+type launchOptions struct {
+	contentID string
+	params    interface{}
+}
+
+// WithContentID launches the app with the provided content ID
+func WithContentID(contentID string) LaunchOption {
+	return func(o *launchOptions) {
+		o.contentID = contentID
+	}
+}
+
+// WithParams launches the app with the provided parameters
+func WithParams(params interface{}) LaunchOption {
+	return func(o *launchOptions) {
+		o.params = params
+	}
+}
+
+// Launch launches the app on the TV using the provided options, if any. It returns the ID of the
+// new session
+func (app *App) Launch(opts ...LaunchOption) (string, error) {
+	var o launchOptions
+	for _, v := range opts {
+		v(&o)
+	}
+
+	return app.tv.LaunchAppWithParams(app.ID, o.contentID, o.params)
+}
+
+// Close closes the app on the TV
+func (app *App) Close() error {
+	return app.tv.CloseApp(app.ID)
+}
+
+// GetState returns whether the app is currently running, and whether it's visible
+func (app *App) GetState() (AppState, error) {
+	return app.tv.GetAppState(app.ID)
+}
+
+// Icon downloads the app's icon from the TV
+func (app *App) Icon(ctx context.Context) ([]byte, error) {
+	fetcher, ok := app.tv.(iconFetcher)
+	if !ok {
+		return nil, ErrNoIcon
+	}
+
+	return fetcher.fetchIcon(ctx, app.ID, app.Version, app.iconURL)
 }
@@ -0,0 +1,116 @@
+package control
+
+import "github.com/dhickie/go-lgtv/connection"
+
+// Batch collects several queries to the TV so that they can be sent together. All the
+// requests in a batch are sent back-to-back, without waiting for the response to one
+// before sending the next, which saves a round trip to the TV for each request.
+//
+// Each query takes a pointer to store its result in, which is populated once Send
+// has been called. Results for queries which fail are left untouched.
+type Batch struct {
+	tv       *LgTv
+	requests []connection.BatchRequest
+	results  []func() error
+}
+
+// NewBatch returns a new empty batch of requests to the TV
+func (tv *LgTv) NewBatch() *Batch {
+	return &Batch{
+		tv: tv,
+	}
+}
+
+// GetVolume adds a request for the current volume of the TV to the batch
+func (b *Batch) GetVolume(volume *int) {
+	var respPayload connection.GetVolumeResponsePayload
+	b.add(uriGetVolume, nil, &respPayload, func() error {
+		*volume = respPayload.Volume
+		return nil
+	})
+}
+
+// GetMute adds a request for the mute status of the TV to the batch
+func (b *Batch) GetMute(mute *bool) {
+	var respPayload connection.GetMuteResponsePayload
+	b.add(uriGetMute, nil, &respPayload, func() error {
+		*mute = respPayload.Mute
+		return nil
+	})
+}
+
+// ListChannels adds a request for the list of available TV channels to the batch
+func (b *Batch) ListChannels(channels *[]Channel) {
+	var respPayload connection.GetChannelListResponsePayload
+	b.add(uriGetChannelList, nil, &respPayload, func() error {
+		result, err := b.tv.convertChannelList(respPayload)
+		if err != nil {
+			return err
+		}
+
+		*channels = result
+		return nil
+	})
+}
+
+// GetCurrentChannel adds a request for the channel the TV is currently set to to the batch
+func (b *Batch) GetCurrentChannel(channel *Channel) {
+	var respPayload connection.GetCurrentChannelResponsePayload
+	b.add(uriGetCurrentChannel, nil, &respPayload, func() error {
+		result, err := b.tv.convertCurrentChannel(respPayload)
+		if err != nil {
+			return err
+		}
+
+		*channel = result
+		return nil
+	})
+}
+
+// ListExternalInputs adds a request for the external input devices of the TV to the batch
+func (b *Batch) ListExternalInputs(inputs *[]Input) {
+	var respPayload connection.GetExternalInputListResponsePayload
+	b.add(uriGetExternalInputList, nil, &respPayload, func() error {
+		*inputs = b.tv.convertInputList(respPayload)
+		return nil
+	})
+}
+
+// ListInstalledApps adds a request for the apps installed on the TV to the batch, which are
+// filtered in the same way as LgTv.ListInstalledApps
+func (b *Batch) ListInstalledApps(apps *[]App, filters ...AppFilter) {
+	var respPayload connection.GetInstalledAppsResponsePayload
+	b.add(uriListApps, nil, &respPayload, func() error {
+		*apps = filterApps(b.tv.convertAppList(respPayload), filters)
+		return nil
+	})
+}
+
+// Len returns the number of requests in the batch
+func (b *Batch) Len() int {
+	return len(b.requests)
+}
+
+// Send sends all the requests in the batch to the TV, and waits for their responses.
+// The returned slice holds the error for each request, in the order the requests were
+// added to the batch. A nil entry means that request succeeded, and its result has
+// been stored.
+func (b *Batch) Send() []error {
+	errs := b.tv.doBatch(b.requests)
+	for i, v := range errs {
+		if v == nil {
+			errs[i] = b.results[i]()
+		}
+	}
+
+	return errs
+}
+
+func (b *Batch) add(uri string, reqPayload interface{}, respPayload interface{}, result func() error) {
+	b.requests = append(b.requests, connection.BatchRequest{
+		URI:      uri,
+		Payload:  reqPayload,
+		Response: respPayload,
+	})
+	b.results = append(b.results, result)
+}
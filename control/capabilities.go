@@ -0,0 +1,221 @@
+package control
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/dhickie/go-lgtv/connection"
+)
+
+// notFoundErrorPrefix starts the error the TV returns for a request to a service or method it doesn't have
+const notFoundErrorPrefix = "404"
+
+// ErrNotSupported is returned when making a request which the TV doesn't support
+var ErrNotSupported = errors.New("Request is not supported by the TV")
+
+// Feature is an area of functionality which not every TV supports
+type Feature string
+
+// Features which the TV may or may not support
+const (
+	FeatureAudio         Feature = "audio"
+	FeatureMediaControls Feature = "mediaControls"
+	FeatureChannels      Feature = "channels"
+	FeatureInputs        Feature = "inputs"
+	FeatureApps          Feature = "apps"
+	FeatureKeyboard      Feature = "keyboard"
+	FeaturePointer       Feature = "pointer"
+	FeatureNotifications Feature = "notifications"
+	FeatureSettings      Feature = "settings"
+	FeaturePower         Feature = "power"
+)
+
+// featureURIs maps the start of request URIs to the feature they're part of. More specific
+// prefixes come first.
+var featureURIs = []struct {
+	prefix  string
+	feature Feature
+}{
+	{"ssap://audio/", FeatureAudio},
+	{"ssap://media.controls/", FeatureMediaControls},
+	{uriSwitchInput, FeatureInputs},
+	{uriGetExternalInputList, FeatureInputs},
+	{"ssap://tv/", FeatureChannels},
+	{"ssap://com.webos.applicationManager/", FeatureApps},
+	{"ssap://system.launcher/", FeatureApps},
+	{"ssap://com.webos.service.ime/", FeatureKeyboard},
+	{"ssap://com.webos.service.networkinput/", FeaturePointer},
+	{"ssap://system.notifications/", FeatureNotifications},
+	{"ssap://settings/", FeatureSettings},
+	{"ssap://com.webos.service.tvpower/", FeaturePower},
+}
+
+// featureServices maps features to the service in the TV's service list which they need. The service
+// list only includes some of the TV's services, so features which aren't here can't be checked
+// against it.
+var featureServices = map[Feature]string{
+	FeatureAudio:         "audio",
+	FeatureMediaControls: "media.controls",
+	FeatureChannels:      "tv",
+	FeatureInputs:        "tv",
+	FeatureApps:          "system.launcher",
+	FeatureNotifications: "system.notifications",
+}
+
+// wholeServiceFeatures are the features which are provided by a single service on the TV. If any
+// request for one of them is rejected because the TV doesn't have it, the whole feature is
+// assumed to be missing.
+var wholeServiceFeatures = map[Feature]bool{
+	FeatureKeyboard: true,
+	FeaturePointer:  true,
+	FeaturePower:    true,
+}
+
+// Capabilities describes what the TV supports
+type Capabilities struct {
+	// Services maps the name of each service listed by the TV to its version, for example "tv" and
+	// "audio". It's nil if the TV didn't provide a service list.
+	Services map[string]int
+	// ReceiverType is the type of broadcast the TV's tuner receives, for example "dvb" or "atsc".
+	// It's empty if the TV didn't say.
+	ReceiverType string
+	// noTuner is true if the TV said its receiver type is "none"
+	noTuner bool
+	// unsupportedFeatures and unsupportedURIs are what the TV has said it doesn't have, when
+	// requests were made to it
+	unsupportedFeatures map[Feature]bool
+	unsupportedURIs     map[string]bool
+}
+
+// Supports returns whether the TV supports the provided feature. This is based on the TV's service
+// list and system information, along with any requests the TV has rejected because it doesn't have
+// the service. If the TV didn't provide this information, features are assumed to be supported.
+func (c Capabilities) Supports(feature Feature) bool {
+	if c.unsupportedFeatures[feature] {
+		return false
+	}
+
+	if service, ok := featureServices[feature]; ok && c.Services != nil {
+		if _, ok := c.Services[service]; !ok {
+			return false
+		}
+	}
+
+	return feature != FeatureChannels || !c.noTuner
+}
+
+// supportsURI returns whether a request to the provided URI is expected to succeed
+func (c Capabilities) supportsURI(uri string) bool {
+	if c.unsupportedURIs[uri] {
+		return false
+	}
+
+	feature, ok := uriFeature(uri)
+	return !ok || c.Supports(feature)
+}
+
+// Capabilities returns what the TV supports. Requests for features which the TV doesn't support fail
+// with ErrNotSupported without being sent.
+func (tv *LgTv) Capabilities() Capabilities {
+	tv.stateLock.Lock()
+	defer tv.stateLock.Unlock()
+
+	return tv.capabilities
+}
+
+func (tv *LgTv) supports(uri string) bool {
+	tv.stateLock.Lock()
+	defer tv.stateLock.Unlock()
+
+	return tv.capabilities.supportsURI(uri)
+}
+
+// checkSupported checks whether the error from a request says that the TV doesn't have the service or
+// method requested. If it does, ErrNotSupported is returned instead, and further requests to the
+// URI, or the whole feature if it's provided by a single service, fail straight away.
+func (tv *LgTv) checkSupported(uri string, err error) error {
+	if err == nil || !strings.HasPrefix(err.Error(), notFoundErrorPrefix) {
+		return err
+	}
+
+	tv.stateLock.Lock()
+	defer tv.stateLock.Unlock()
+
+	// Capabilities are handed out by value, so the maps are copied rather than changed
+	c := &tv.capabilities
+	if feature, ok := uriFeature(uri); ok && wholeServiceFeatures[feature] {
+		features := make(map[Feature]bool, len(c.unsupportedFeatures)+1)
+		for k, v := range c.unsupportedFeatures {
+			features[k] = v
+		}
+		features[feature] = true
+		c.unsupportedFeatures = features
+	} else {
+		uris := make(map[string]bool, len(c.unsupportedURIs)+1)
+		for k, v := range c.unsupportedURIs {
+			uris[k] = v
+		}
+		uris[uri] = true
+		c.unsupportedURIs = uris
+	}
+
+	return ErrNotSupported
+}
+
+// loadCapabilities asks the TV which services it has, and whether it has a tuner. What the TV has
+// previously said it doesn't have is kept from the provided capabilities, since that doesn't change
+// between connections.
+func loadCapabilities(conn *connection.Connection, previous Capabilities) Capabilities {
+	var servicesPayload connection.GetServiceListResponsePayload
+	var systemPayload connection.GetSystemInfoResponsePayload
+	errs := conn.RequestBatch([]connection.BatchRequest{
+		{URI: uriGetServiceList, Response: &servicesPayload},
+		{URI: uriGetSystemInfo, Response: &systemPayload},
+	})
+
+	services, system := &servicesPayload, &systemPayload
+	if errs[0] != nil {
+		services = nil
+	}
+	if errs[1] != nil {
+		system = nil
+	}
+
+	return newCapabilities(previous, services, system)
+}
+
+// newCapabilities returns capabilities based on the TV's service list and system information,
+// either of which can be nil if the TV didn't provide them
+func newCapabilities(previous Capabilities, services *connection.GetServiceListResponsePayload,
+	system *connection.GetSystemInfoResponsePayload) Capabilities {
+	c := Capabilities{
+		unsupportedFeatures: previous.unsupportedFeatures,
+		unsupportedURIs:     previous.unsupportedURIs,
+	}
+
+	if services != nil && len(services.Services) > 0 {
+		c.Services = make(map[string]int, len(services.Services))
+		for _, v := range services.Services {
+			c.Services[v.Name] = v.Version
+		}
+	}
+
+	// Only a receiver type of "none" means there's no tuner. An empty one says nothing either way.
+	if system != nil && system.ReturnValue {
+		c.ReceiverType = system.ReceiverType
+		c.noTuner = strings.EqualFold(c.ReceiverType, "none")
+	}
+
+	return c
+}
+
+// uriFeature returns the feature a request URI is part of, if it's part of one
+func uriFeature(uri string) (Feature, bool) {
+	for _, v := range featureURIs {
+		if strings.HasPrefix(uri, v.prefix) {
+			return v.feature, true
+		}
+	}
+
+	return "", false
+}
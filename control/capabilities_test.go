@@ -0,0 +1,83 @@
+package control
+
+import (
+	"testing"
+
+	"github.com/dhickie/go-lgtv/connection"
+)
+
+func TestCapabilitiesSupportsURI(t *testing.T) {
+	// The service list reported by most webOS TVs, which doesn't include settings or any of the
+	// com.webos services
+	services := map[string]int{
+		"api": 1, "audio": 1, "media.controls": 1, "media.viewer": 1, "pairing": 1, "system": 1,
+		"system.launcher": 1, "system.notifications": 1, "tv": 1, "webapp": 1,
+	}
+
+	tests := []struct {
+		name         string
+		capabilities Capabilities
+		uri          string
+		want         bool
+	}{
+		{"no information", Capabilities{}, uriGetChannelList, true},
+		{"listed service", Capabilities{Services: services}, uriGetVolume, true},
+		{"settings not in list", Capabilities{Services: services}, uriGetSystemSettings, true},
+		{"ime not in list", Capabilities{Services: services}, uriInsertText, true},
+		{"unlisted service", Capabilities{Services: map[string]int{"tv": 1}}, uriGetVolume, false},
+		{"channels without tuner", Capabilities{Services: services, noTuner: true}, uriGetChannelList, false},
+		{"inputs without tuner", Capabilities{Services: services, noTuner: true}, uriSwitchInput, true},
+		{"unsupported feature", Capabilities{unsupportedFeatures: map[Feature]bool{FeatureKeyboard: true}}, uriSendEnterKey, false},
+		{"unsupported uri", Capabilities{unsupportedURIs: map[string]bool{uriGetChannelProgramInfo: true}}, uriGetChannelProgramInfo, false},
+		{"other uri of unsupported uri's feature", Capabilities{unsupportedURIs: map[string]bool{uriGetChannelProgramInfo: true}}, uriSetChannel, true},
+		{"unmapped uri", Capabilities{Services: services, noTuner: true}, uriGetSoftwareInfo, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.capabilities.supportsURI(tt.uri); got != tt.want {
+				t.Errorf("supportsURI(%q) = %v, want %v", tt.uri, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCapabilities(t *testing.T) {
+	services := &connection.GetServiceListResponsePayload{
+		ReturnValue: true,
+		Services:    []connection.Service{{Name: "tv", Version: 1}, {Name: "audio", Version: 1}},
+	}
+	previous := Capabilities{unsupportedFeatures: map[Feature]bool{FeatureKeyboard: true}}
+
+	tests := []struct {
+		name         string
+		system       *connection.GetSystemInfoResponsePayload
+		wantReceiver string
+		wantChannels bool
+	}{
+		{"no system info", nil, "", true},
+		{"system info failed", &connection.GetSystemInfoResponsePayload{ReturnValue: false, ReceiverType: "none"}, "", true},
+		{"empty receiver type", &connection.GetSystemInfoResponsePayload{ReturnValue: true}, "", true},
+		{"no receiver", &connection.GetSystemInfoResponsePayload{ReturnValue: true, ReceiverType: "none"}, "none", false},
+		{"dvb receiver", &connection.GetSystemInfoResponsePayload{ReturnValue: true, ReceiverType: "dvb"}, "dvb", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCapabilities(previous, services, tt.system)
+
+			if c.ReceiverType != tt.wantReceiver {
+				t.Errorf("Expected receiver type %q, got %q", tt.wantReceiver, c.ReceiverType)
+			}
+			if got := c.Supports(FeatureChannels); got != tt.wantChannels {
+				t.Errorf("Supports(FeatureChannels) = %v, want %v", got, tt.wantChannels)
+			}
+			if !c.Supports(FeatureInputs) {
+				t.Error("Expected inputs to be supported")
+			}
+			if c.Supports(FeatureKeyboard) {
+				t.Error("Expected the previously unsupported keyboard to stay unsupported")
+			}
+		})
+	}
+}
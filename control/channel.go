@@ -6,7 +6,7 @@ type Channel struct {
 	ChannelNumber int
 	IsScrambled   bool
 	IsHdtv        bool
-	tv            *LgTv
+	tv            Channels
 }
 
 // Watch switches the TV to this channel
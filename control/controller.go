@@ -0,0 +1,143 @@
+package control
+
+import "context"
+
+// ConnectionStatus is implemented by anything which can report the state of its connection to a TV
+type ConnectionStatus interface {
+	State() ConnectionState
+	IsConnected() bool
+	OnStateChange(handler StateChangeHandler)
+}
+
+// Audio is implemented by anything which can control the volume of a TV
+type Audio interface {
+	VolumeUp() error
+	VolumeDown() error
+	SetVolume(value int) error
+	GetVolume() (int, error)
+	SetMute(isMute bool) error
+	GetMute() (bool, error)
+}
+
+// Media is implemented by anything which can control media playback on a TV
+type Media interface {
+	Play() error
+	Pause() error
+	Stop() error
+	Rewind() error
+	FastForward() error
+}
+
+// Channels is implemented by anything which can query and change the channel of a TV
+type Channels interface {
+	ChannelUp() error
+	ChannelDown() error
+	SetChannel(channelNumber int) error
+	ListChannels() ([]Channel, error)
+	GetCurrentChannel() (Channel, error)
+	GetChannelProgramList() (ChannelProgramList, error)
+	FindChannel(query string) ([]Channel, error)
+}
+
+// Inputs is implemented by anything which can query and switch the external inputs of a TV
+type Inputs interface {
+	SwitchInput(inputID string) error
+	ListExternalInputs() ([]Input, error)
+	FindInput(query string) ([]Input, error)
+	SwitchInputByLabel(label string) error
+}
+
+// Apps is implemented by anything which can query and launch apps on a TV
+type Apps interface {
+	ListInstalledApps(filters ...AppFilter) ([]App, error)
+	ListRunningApps() ([]App, error)
+	ListLaunchPoints() ([]LaunchPoint, error)
+	LaunchApp(appID string) (string, error)
+	LaunchAppWithParams(appID, contentID string, params interface{}) (string, error)
+	LaunchDeepLink(link DeepLink) (string, error)
+	OpenURL(url string) (string, error)
+	CloseApp(appID string) error
+	CloseSession(sessionID string) error
+	GetAppState(appID string) (AppState, error)
+	GetForegroundApp() (ForegroundApp, error)
+	FindApp(query string) ([]App, error)
+	LaunchAppByName(name string) (string, error)
+	LaunchAppAndWait(ctx context.Context, appID string) (string, error)
+}
+
+// RemoteControl is implemented by anything which can press buttons on a TV's remote control
+type RemoteControl interface {
+	PressKeys(keys ...Key) error
+}
+
+// PointerInput is implemented by anything which can move and click a TV's pointer. It is satisfied
+// by Pointer, and by FakePointer for use in tests. Pointers are created by LgTv.Pointer and
+// FakeTV.Pointer, which aren't part of Controller since they return different types.
+type PointerInput interface {
+	Move(dx, dy float64)
+	Drag(dx, dy float64)
+	Scroll(dx, dy float64)
+	Click() error
+	Err() error
+	Close() error
+}
+
+// Keyboard is implemented by anything which can type text in to the focused field on a TV
+type Keyboard interface {
+	InsertText(text string, replace bool) error
+	DeleteCharacters(count int) error
+	SendEnterKey() error
+	TypeText(text string, opts *TypeTextOptions) error
+}
+
+// Notifications is implemented by anything which can show notifications on a TV
+type Notifications interface {
+	ShowToast(message string, opts *ToastOptions) (string, error)
+	ShowAlert(message string, buttons []AlertButton, opts *AlertOptions) (*Alert, error)
+}
+
+// Power is implemented by anything which can turn a TV on and off
+type Power interface {
+	TurnOn() error
+	TurnOff() error
+	GetPowerState() (PowerStatus, error)
+}
+
+// Info is implemented by anything which can describe a TV and what it supports
+type Info interface {
+	GetInfo() (TVInfo, error)
+	Capabilities() Capabilities
+}
+
+// Subscriptions is implemented by anything which can send changes on a TV as they happen
+type Subscriptions interface {
+	SubscribeKeyboardFocus(ctx context.Context) (<-chan KeyboardEvent, error)
+	SubscribeForegroundApp(ctx context.Context) (<-chan ForegroundApp, error)
+	SubscribeLaunchPoints(ctx context.Context) (<-chan LaunchPointChange, error)
+	SubscribePowerState(ctx context.Context) (<-chan PowerStatus, error)
+}
+
+// Controller is implemented by anything which can fully control a TV. It is satisfied
+// by LgTv, and by FakeTV for use in tests.
+type Controller interface {
+	Connect(clientKey string, timeout int) (string, error)
+	Disconnect() error
+
+	ConnectionStatus
+	Audio
+	Media
+	Channels
+	Inputs
+	Apps
+	RemoteControl
+	Keyboard
+	Notifications
+	Power
+	Info
+	Subscriptions
+}
+
+var (
+	_ Controller   = (*LgTv)(nil)
+	_ PointerInput = (*Pointer)(nil)
+)
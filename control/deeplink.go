@@ -0,0 +1,52 @@
+package control
+
+import (
+	"fmt"
+	"net/url"
+)
+
+const (
+	youTubeAppID = "youtube.leanback.v4"
+	netflixAppID = "netflix"
+)
+
+// DeepLink is a link to a particular piece of content in an app on the TV
+type DeepLink struct {
+	AppID     string
+	ContentID string
+	Params    interface{}
+}
+
+// YouTubeVideo returns a deep link which plays the YouTube video with the provided ID
+func YouTubeVideo(videoID string) DeepLink {
+	return DeepLink{
+		AppID: youTubeAppID,
+		Params: map[string]string{
+			"contentTarget": fmt.Sprintf("https://www.youtube.com/tv?v=%v", url.QueryEscape(videoID)),
+		},
+	}
+}
+
+// NetflixTitle returns a deep link which opens the Netflix title with the provided ID
+func NetflixTitle(titleID string) DeepLink {
+	return DeepLink{
+		AppID:     netflixAppID,
+		ContentID: fmt.Sprintf("m=https://api.netflix.com/catalog/titles/movies/%v&source_type=4", url.QueryEscape(titleID)),
+	}
+}
+
+// BrowserURL returns a deep link which opens the provided URL in the TV's web browser
+func BrowserURL(target string) DeepLink {
+	return DeepLink{
+		AppID: browserAppID,
+		Params: map[string]string{
+			"target": target,
+		},
+	}
+}
+
+// LaunchDeepLink launches the app the deep link is for, opening its content. If successfully
+// launched, it returns the ID of the new session
+func (tv *LgTv) LaunchDeepLink(link DeepLink) (string, error) {
+	return tv.LaunchAppWithParams(link.AppID, link.ContentID, link.Params)
+}
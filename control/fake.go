@@ -0,0 +1,1426 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrFakeNotFound is returned by FakeTV when asked to use a channel, input or app which
+// isn't part of its configured state
+var ErrFakeNotFound = errors.New("Item not found in fake TV state")
+
+// FakeCall is a record of a single method call made to a FakeTV
+type FakeCall struct {
+	Method string
+	Args   []interface{}
+}
+
+// FakeTV is an in-memory implementation of Controller for use in tests. Its exported
+// fields hold the state of the fake TV, and can be set up before use. Every call made to
+// it is recorded, and can be retrieved using Calls.
+//
+// An error can be injected for any method by adding it to Errors, keyed by method name.
+// Icons for apps and inputs are looked up in Icons, keyed by app or input ID.
+// Channels, inputs and apps returned by the fake are bound to it, so calling Watch,
+// Switch or Launch on them is recorded against the fake as well.
+//
+// Changes made through the fake's methods, such as launching an app or turning it off, are
+// sent to its subscribers. Changes which would come from the TV itself can be simulated using
+// SetState, SetForegroundApp, SetKeyboardFocus, SetPowerState, PressAlertButton and DismissAlert.
+type FakeTV struct {
+	ClientKey      string
+	Connected      bool
+	IsOn           bool
+	Volume         int
+	Muted          bool
+	Channels       []Channel
+	CurrentChannel int
+	Programs       []Program
+	Inputs         []Input
+	CurrentInput   string
+	Apps           []App
+	CurrentApp     string
+	RunningApps    []string
+	LaunchPoints   []LaunchPoint
+	Icons          map[string][]byte
+	Text           string
+	PressedKeys    []Key
+	Toasts         []string
+	Alerts         []string
+	Info           TVInfo
+	// UnsupportedFeatures are the features the fake reports it doesn't support from Capabilities
+	UnsupportedFeatures []Feature
+	Errors              map[string]error
+
+	lock            sync.Mutex
+	calls           []FakeCall
+	lastSession     int
+	sessions        map[string]string
+	state           ConnectionState
+	stateHandlers   []StateChangeHandler
+	keyboardFocused bool
+	powerState      PowerStatus
+	openAlert       *fakeAlert
+	subscribers     []*fakeSubscriber
+}
+
+// fakeAlert is an alert shown by the fake, which hasn't been closed yet
+type fakeAlert struct {
+	alert   *Alert
+	buttons int
+	presses chan int
+}
+
+// fakeSubscriber is a subscription to changes in the fake. Changes are queued, so that
+// making changes to the fake never waits for subscribers to receive them.
+type fakeSubscriber struct {
+	kind  string
+	lock  sync.Mutex
+	queue []interface{}
+	wake  chan struct{}
+}
+
+// Kinds of change which can be subscribed to
+const (
+	fakeSubKeyboard     = "keyboard"
+	fakeSubForeground   = "foreground"
+	fakeSubLaunchPoints = "launchPoints"
+	fakeSubPower        = "power"
+)
+
+var _ Controller = (*FakeTV)(nil)
+
+// NewFakeTV returns a new FakeTV which is turned on, with no channels, inputs or apps
+func NewFakeTV() *FakeTV {
+	return &FakeTV{
+		IsOn:     true,
+		Errors:   make(map[string]error),
+		sessions: make(map[string]string),
+	}
+}
+
+// Calls returns a copy of every call made to the fake so far, in the order they were made
+func (f *FakeTV) Calls() []FakeCall {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	calls := make([]FakeCall, len(f.calls))
+	copy(calls, f.calls)
+	return calls
+}
+
+// CallsTo returns the calls made to the method with the provided name
+func (f *FakeTV) CallsTo(method string) []FakeCall {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var calls []FakeCall
+	for _, v := range f.calls {
+		if v.Method == method {
+			calls = append(calls, v)
+		}
+	}
+	return calls
+}
+
+// Reset clears the record of calls made to the fake
+func (f *FakeTV) Reset() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.calls = nil
+}
+
+// Connect records the call and marks the fake as connected. If an empty client key is
+// provided, a new one is generated.
+func (f *FakeTV) Connect(clientKey string, timeout int) (string, error) {
+	f.lock.Lock()
+	if err := f.record("Connect", clientKey, timeout); err != nil {
+		f.lock.Unlock()
+		return "", err
+	}
+
+	if clientKey == "" {
+		clientKey = "fake-client-key"
+	}
+	f.ClientKey = clientKey
+	f.lock.Unlock()
+
+	f.SetState(StateConnected)
+	return clientKey, nil
+}
+
+// Disconnect records the call and marks the fake as closed
+func (f *FakeTV) Disconnect() error {
+	if err := f.recordOnly("Disconnect"); err != nil {
+		return err
+	}
+
+	f.SetState(StateClosed)
+	return nil
+}
+
+// State returns the state of the fake's connection. If Connected has been set directly,
+// it takes priority.
+func (f *FakeTV) State() ConnectionState {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	switch {
+	case f.Connected:
+		return StateConnected
+	case f.state == StateConnected:
+		return StateDisconnected
+	default:
+		return f.state
+	}
+}
+
+// IsConnected returns whether the fake is connected
+func (f *FakeTV) IsConnected() bool {
+	return f.State() == StateConnected
+}
+
+// OnStateChange adds a handler which is called whenever the state of the fake's connection changes
+func (f *FakeTV) OnStateChange(handler StateChangeHandler) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.stateHandlers = append(f.stateHandlers, handler)
+}
+
+// SetState changes the state of the fake's connection, and calls its state change handlers. It
+// isn't recorded as a call.
+func (f *FakeTV) SetState(state ConnectionState) {
+	oldState := f.State()
+
+	f.lock.Lock()
+	f.state = state
+	f.Connected = state == StateConnected
+	handlers := f.stateHandlers
+	f.lock.Unlock()
+
+	if oldState == state {
+		return
+	}
+
+	for _, v := range handlers {
+		v(oldState, state)
+	}
+}
+
+// VolumeUp records the call and increases the fake's volume by 1
+func (f *FakeTV) VolumeUp() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("VolumeUp"); err != nil {
+		return err
+	}
+
+	f.Volume = clampVolume(f.Volume + 1)
+	return nil
+}
+
+// VolumeDown records the call and decreases the fake's volume by 1
+func (f *FakeTV) VolumeDown() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("VolumeDown"); err != nil {
+		return err
+	}
+
+	f.Volume = clampVolume(f.Volume - 1)
+	return nil
+}
+
+// SetVolume records the call and sets the fake's volume
+func (f *FakeTV) SetVolume(value int) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("SetVolume", value); err != nil {
+		return err
+	}
+
+	f.Volume = clampVolume(value)
+	return nil
+}
+
+// GetVolume records the call and returns the fake's volume
+func (f *FakeTV) GetVolume() (int, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("GetVolume"); err != nil {
+		return 0, err
+	}
+
+	return f.Volume, nil
+}
+
+// SetMute records the call and sets the fake's mute status
+func (f *FakeTV) SetMute(isMute bool) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("SetMute", isMute); err != nil {
+		return err
+	}
+
+	f.Muted = isMute
+	return nil
+}
+
+// GetMute records the call and returns the fake's mute status
+func (f *FakeTV) GetMute() (bool, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("GetMute"); err != nil {
+		return false, err
+	}
+
+	return f.Muted, nil
+}
+
+// Play records the call
+func (f *FakeTV) Play() error {
+	return f.recordOnly("Play")
+}
+
+// Pause records the call
+func (f *FakeTV) Pause() error {
+	return f.recordOnly("Pause")
+}
+
+// Stop records the call
+func (f *FakeTV) Stop() error {
+	return f.recordOnly("Stop")
+}
+
+// Rewind records the call
+func (f *FakeTV) Rewind() error {
+	return f.recordOnly("Rewind")
+}
+
+// FastForward records the call
+func (f *FakeTV) FastForward() error {
+	return f.recordOnly("FastForward")
+}
+
+// ChannelUp records the call and moves the fake to the next channel in its channel list
+func (f *FakeTV) ChannelUp() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("ChannelUp"); err != nil {
+		return err
+	}
+
+	f.stepChannel(1)
+	return nil
+}
+
+// ChannelDown records the call and moves the fake to the previous channel in its channel list
+func (f *FakeTV) ChannelDown() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("ChannelDown"); err != nil {
+		return err
+	}
+
+	f.stepChannel(-1)
+	return nil
+}
+
+// SetChannel records the call and sets the fake's current channel
+func (f *FakeTV) SetChannel(channelNumber int) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("SetChannel", channelNumber); err != nil {
+		return err
+	}
+
+	f.CurrentChannel = channelNumber
+	return nil
+}
+
+// ListChannels records the call and returns the fake's channel list
+func (f *FakeTV) ListChannels() ([]Channel, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("ListChannels"); err != nil {
+		return nil, err
+	}
+
+	return f.channels(), nil
+}
+
+// FindChannel records the call and looks up the fake's channels in the same way as LgTv.FindChannel
+func (f *FakeTV) FindChannel(query string) ([]Channel, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("FindChannel", query); err != nil {
+		return nil, err
+	}
+
+	return findChannels(f.channels(), query)
+}
+
+// GetCurrentChannel records the call and returns the fake's current channel
+func (f *FakeTV) GetCurrentChannel() (Channel, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("GetCurrentChannel"); err != nil {
+		return Channel{}, err
+	}
+
+	return f.currentChannel()
+}
+
+// GetChannelProgramList records the call and returns the fake's programs for the current channel
+func (f *FakeTV) GetChannelProgramList() (ChannelProgramList, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("GetChannelProgramList"); err != nil {
+		return ChannelProgramList{}, err
+	}
+
+	channel, err := f.currentChannel()
+	if err != nil {
+		return ChannelProgramList{}, err
+	}
+
+	programs := make([]Program, len(f.Programs))
+	copy(programs, f.Programs)
+	return ChannelProgramList{
+		Channel:  channel,
+		Programs: programs,
+	}, nil
+}
+
+// SwitchInput records the call and sets the fake's current input
+func (f *FakeTV) SwitchInput(inputID string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("SwitchInput", inputID); err != nil {
+		return err
+	}
+
+	f.CurrentInput = inputID
+	return nil
+}
+
+// ListExternalInputs records the call and returns the fake's inputs
+func (f *FakeTV) ListExternalInputs() ([]Input, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("ListExternalInputs"); err != nil {
+		return nil, err
+	}
+
+	return f.inputs(), nil
+}
+
+// FindInput records the call and looks up the fake's inputs in the same way as LgTv.FindInput
+func (f *FakeTV) FindInput(query string) ([]Input, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("FindInput", query); err != nil {
+		return nil, err
+	}
+
+	return findInputs(f.inputs(), query)
+}
+
+// SwitchInputByLabel records the call and sets the fake's current input to the one which best
+// matches the label
+func (f *FakeTV) SwitchInputByLabel(label string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("SwitchInputByLabel", label); err != nil {
+		return err
+	}
+
+	inputs, err := findInputs(f.inputs(), label)
+	if err != nil {
+		return err
+	}
+
+	f.CurrentInput = inputs[0].ID
+	return nil
+}
+
+// ListInstalledApps records the call and returns the fake's apps which are included by the filters
+func (f *FakeTV) ListInstalledApps(filters ...AppFilter) ([]App, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("ListInstalledApps"); err != nil {
+		return nil, err
+	}
+
+	return filterApps(f.apps(), filters), nil
+}
+
+// ListRunningApps records the call and returns the fake's running apps
+func (f *FakeTV) ListRunningApps() ([]App, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("ListRunningApps"); err != nil {
+		return nil, err
+	}
+
+	apps := make([]App, len(f.RunningApps))
+	for i, v := range f.RunningApps {
+		app := App{
+			Name: v,
+			ID:   v,
+		}
+		for _, installed := range f.Apps {
+			if installed.ID == v {
+				app = installed
+				break
+			}
+		}
+
+		app.State = AppState{
+			Running: true,
+			Visible: f.CurrentApp == v,
+		}
+		app.tv = f
+		apps[i] = app
+	}
+
+	return apps, nil
+}
+
+// ListLaunchPoints records the call and returns the fake's launch points
+func (f *FakeTV) ListLaunchPoints() ([]LaunchPoint, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("ListLaunchPoints"); err != nil {
+		return nil, err
+	}
+
+	launchPoints := make([]LaunchPoint, len(f.LaunchPoints))
+	for i, v := range f.LaunchPoints {
+		v.tv = f
+		launchPoints[i] = v
+	}
+
+	return launchPoints, nil
+}
+
+// LaunchApp records the call and sets the fake's current app. It returns ErrFakeNotFound
+// if the app isn't one of the fake's apps.
+func (f *FakeTV) LaunchApp(appID string) (string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("LaunchApp", appID); err != nil {
+		return "", err
+	}
+
+	if !f.hasApp(appID) {
+		return "", ErrFakeNotFound
+	}
+
+	return f.launch(appID), nil
+}
+
+// LaunchAppWithParams records the call and sets the fake's current app. It returns
+// ErrFakeNotFound if the app isn't one of the fake's apps.
+func (f *FakeTV) LaunchAppWithParams(appID, contentID string, params interface{}) (string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("LaunchAppWithParams", appID, contentID, params); err != nil {
+		return "", err
+	}
+
+	if !f.hasApp(appID) {
+		return "", ErrFakeNotFound
+	}
+
+	return f.launch(appID), nil
+}
+
+// LaunchDeepLink records the call and sets the fake's current app to the one the link is for.
+// It returns ErrFakeNotFound if the app isn't one of the fake's apps.
+func (f *FakeTV) LaunchDeepLink(link DeepLink) (string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("LaunchDeepLink", link); err != nil {
+		return "", err
+	}
+
+	if !f.hasApp(link.AppID) {
+		return "", ErrFakeNotFound
+	}
+
+	return f.launch(link.AppID), nil
+}
+
+// OpenURL records the call and sets the fake's current app to the web browser
+func (f *FakeTV) OpenURL(url string) (string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("OpenURL", url); err != nil {
+		return "", err
+	}
+
+	return f.launch(browserAppID), nil
+}
+
+// CloseApp records the call and closes the app in the fake
+func (f *FakeTV) CloseApp(appID string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("CloseApp", appID); err != nil {
+		return err
+	}
+
+	f.close(appID)
+	return nil
+}
+
+// CloseSession records the call and closes the app the session belongs to in the fake.
+// It returns ErrFakeNotFound if the session wasn't started by the fake.
+func (f *FakeTV) CloseSession(sessionID string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("CloseSession", sessionID); err != nil {
+		return err
+	}
+
+	appID, ok := f.sessions[sessionID]
+	if !ok {
+		return ErrFakeNotFound
+	}
+
+	f.close(appID)
+	return nil
+}
+
+// GetAppState records the call and returns the state of the app in the fake
+func (f *FakeTV) GetAppState(appID string) (AppState, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("GetAppState", appID); err != nil {
+		return AppState{}, err
+	}
+
+	return AppState{
+		Running: f.isRunning(appID),
+		Visible: f.CurrentApp == appID,
+	}, nil
+}
+
+// GetForegroundApp records the call and returns the fake's current app
+func (f *FakeTV) GetForegroundApp() (ForegroundApp, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("GetForegroundApp"); err != nil {
+		return ForegroundApp{}, err
+	}
+
+	return ForegroundApp{
+		AppID: f.CurrentApp,
+	}, nil
+}
+
+// FindApp records the call and looks up the fake's apps in the same way as LgTv.FindApp. Like the
+// TV, only apps which are visible are searched.
+func (f *FakeTV) FindApp(query string) ([]App, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("FindApp", query); err != nil {
+		return nil, err
+	}
+
+	return findApps(filterApps(f.apps(), []AppFilter{VisibleOnly}), query)
+}
+
+// LaunchAppByName records the call and launches the fake's visible app which best matches the name
+func (f *FakeTV) LaunchAppByName(name string) (string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("LaunchAppByName", name); err != nil {
+		return "", err
+	}
+
+	apps, err := findApps(filterApps(f.apps(), []AppFilter{VisibleOnly}), name)
+	if err != nil {
+		return "", err
+	}
+
+	return f.launch(apps[0].ID), nil
+}
+
+// LaunchAppAndWait records the call and sets the fake's current app, which happens straight away.
+// It returns ErrFakeNotFound if the app isn't one of the fake's apps.
+func (f *FakeTV) LaunchAppAndWait(ctx context.Context, appID string) (string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("LaunchAppAndWait", appID); err != nil {
+		return "", err
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	if !f.hasApp(appID) {
+		return "", ErrFakeNotFound
+	}
+
+	return f.launch(appID), nil
+}
+
+// SetForegroundApp changes the fake's current app without launching it, as if it had been changed
+// on the TV. It isn't recorded as a call.
+func (f *FakeTV) SetForegroundApp(appID string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.setCurrentApp(appID)
+}
+
+// PressKeys records the call and adds the keys to the fake's pressed keys
+func (f *FakeTV) PressKeys(keys ...Key) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	args := make([]interface{}, len(keys))
+	for i, v := range keys {
+		args[i] = v
+	}
+	if err := f.record("PressKeys", args...); err != nil {
+		return err
+	}
+
+	f.PressedKeys = append(f.PressedKeys, keys...)
+	return nil
+}
+
+// InsertText records the call and adds the text to the fake's focused text field
+func (f *FakeTV) InsertText(text string, replace bool) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("InsertText", text, replace); err != nil {
+		return err
+	}
+
+	if replace {
+		f.Text = ""
+	}
+	f.Text += text
+	f.publish(fakeSubKeyboard, f.keyboardEvent())
+	return nil
+}
+
+// DeleteCharacters records the call and deletes characters from the end of the fake's
+// focused text field
+func (f *FakeTV) DeleteCharacters(count int) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("DeleteCharacters", count); err != nil {
+		return err
+	}
+
+	runes := []rune(f.Text)
+	if count > len(runes) {
+		count = len(runes)
+	}
+	f.Text = string(runes[:len(runes)-count])
+	f.publish(fakeSubKeyboard, f.keyboardEvent())
+	return nil
+}
+
+// SendEnterKey records the call
+func (f *FakeTV) SendEnterKey() error {
+	return f.recordOnly("SendEnterKey")
+}
+
+// TypeText records the call and types the text in to the fake's focused text field
+func (f *FakeTV) TypeText(text string, opts *TypeTextOptions) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("TypeText", text, opts); err != nil {
+		return err
+	}
+
+	if opts != nil && opts.Replace {
+		f.Text = ""
+	}
+	f.Text += text
+	f.publish(fakeSubKeyboard, f.keyboardEvent())
+	return nil
+}
+
+// SetKeyboardFocus changes whether a text field has focus in the fake, as if it had been focused
+// on the TV. It isn't recorded as a call.
+func (f *FakeTV) SetKeyboardFocus(focused bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.keyboardFocused = focused
+	f.publish(fakeSubKeyboard, f.keyboardEvent())
+}
+
+// ShowToast records the call and adds the message to the fake's toasts
+func (f *FakeTV) ShowToast(message string, opts *ToastOptions) (string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("ShowToast", message, opts); err != nil {
+		return "", err
+	}
+
+	f.Toasts = append(f.Toasts, message)
+	return fmt.Sprintf("fake-toast-%v", len(f.Toasts)), nil
+}
+
+// ShowAlert records the call and adds the message to the fake's alerts. The alert stays open until
+// it times out, is closed, or it's closed or a button is pressed on the TV using DismissAlert or
+// PressAlertButton.
+func (f *FakeTV) ShowAlert(message string, buttons []AlertButton, opts *AlertOptions) (*Alert, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("ShowAlert", message, buttons, opts); err != nil {
+		return nil, err
+	}
+
+	if len(buttons) == 0 {
+		return nil, ErrNoAlertButtons
+	}
+
+	f.Alerts = append(f.Alerts, message)
+	id := fmt.Sprintf("fake-alert-%v", len(f.Alerts))
+	alert := newAlert(func() error {
+		return f.recordOnly("CloseAlert", id)
+	})
+	alert.ID = id
+
+	presses := make(chan int, 1)
+	f.openAlert = &fakeAlert{
+		alert:   alert,
+		buttons: len(buttons),
+		presses: presses,
+	}
+
+	var timeout time.Duration
+	if opts != nil {
+		timeout = opts.Timeout
+	}
+
+	go alert.watch(presses, buttons, timeout)
+
+	return alert, nil
+}
+
+// PressAlertButton presses the button with the provided index on the alert most recently shown
+// by the fake, as if it had been pressed on the TV. It returns ErrFakeNotFound if there's no open
+// alert, or it doesn't have the button. It isn't recorded as a call.
+func (f *FakeTV) PressAlertButton(index int) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.openAlert == nil || index < 0 || index >= f.openAlert.buttons {
+		return ErrFakeNotFound
+	}
+
+	return f.sendToAlert(index)
+}
+
+// DismissAlert closes the alert most recently shown by the fake without pressing a button, as if
+// BACK or EXIT had been pressed on the TV. It returns ErrFakeNotFound if there's no open alert.
+// It isn't recorded as a call.
+func (f *FakeTV) DismissAlert() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.openAlert == nil {
+		return ErrFakeNotFound
+	}
+
+	return f.sendToAlert(alertClosed)
+}
+
+// Pointer records the call and returns a fake pointer for the fake
+func (f *FakeTV) Pointer(opts *PointerOptions) *FakePointer {
+	f.recordOnly("Pointer", opts)
+
+	return &FakePointer{
+		tv: f,
+	}
+}
+
+// FakePointer is an in-memory implementation of PointerInput, returned by FakeTV.Pointer.
+// Movement is added up in its exported fields, rather than being sent anywhere. Clicks are
+// recorded against the fake TV as calls to "PointerClick", so errors can be injected for them.
+type FakePointer struct {
+	MoveX   float64
+	MoveY   float64
+	DragX   float64
+	DragY   float64
+	ScrollX float64
+	ScrollY float64
+	Clicks  int
+	Closed  bool
+
+	tv   *FakeTV
+	lock sync.Mutex
+}
+
+var _ PointerInput = (*FakePointer)(nil)
+
+// Move adds to the fake pointer's movement
+func (p *FakePointer) Move(dx, dy float64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.MoveX += dx
+	p.MoveY += dy
+}
+
+// Drag adds to the fake pointer's drag
+func (p *FakePointer) Drag(dx, dy float64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.DragX += dx
+	p.DragY += dy
+}
+
+// Scroll adds to the fake pointer's scroll
+func (p *FakePointer) Scroll(dx, dy float64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.ScrollX += dx
+	p.ScrollY += dy
+}
+
+// Click records the call against the fake TV and counts the click
+func (p *FakePointer) Click() error {
+	if err := p.tv.recordOnly("PointerClick"); err != nil {
+		return err
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.Clicks++
+	return nil
+}
+
+// Err always returns nil, since the fake pointer never sends anything in the background
+func (p *FakePointer) Err() error {
+	return nil
+}
+
+// Close marks the fake pointer as closed
+func (p *FakePointer) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.Closed = true
+	return nil
+}
+
+// TurnOn records the call and turns the fake on
+func (f *FakeTV) TurnOn() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("TurnOn"); err != nil {
+		return err
+	}
+
+	f.IsOn = true
+	f.powerState = PowerStatus{}
+	f.publish(fakeSubPower, f.currentPowerState())
+	return nil
+}
+
+// TurnOff records the call and turns the fake off
+func (f *FakeTV) TurnOff() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("TurnOff"); err != nil {
+		return err
+	}
+
+	f.IsOn = false
+	f.powerState = PowerStatus{}
+	f.publish(fakeSubPower, f.currentPowerState())
+	return nil
+}
+
+// GetPowerState records the call and returns the fake's power state. Unless it's been set using
+// SetPowerState, the fake is either active or suspended, depending on whether it's on.
+func (f *FakeTV) GetPowerState() (PowerStatus, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("GetPowerState"); err != nil {
+		return PowerStatus{}, err
+	}
+
+	return f.currentPowerState(), nil
+}
+
+// SetPowerState changes the fake's power state, as if it had changed on the TV. It isn't recorded
+// as a call.
+func (f *FakeTV) SetPowerState(status PowerStatus) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.powerState = status
+	f.IsOn = status.IsOn()
+	f.publish(fakeSubPower, status)
+}
+
+// GetInfo records the call and returns the fake's info
+func (f *FakeTV) GetInfo() (TVInfo, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("GetInfo"); err != nil {
+		return TVInfo{}, err
+	}
+
+	return f.Info, nil
+}
+
+// Capabilities returns capabilities which support everything except the fake's unsupported features.
+// It isn't recorded as a call.
+func (f *FakeTV) Capabilities() Capabilities {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	unsupported := make(map[Feature]bool, len(f.UnsupportedFeatures))
+	for _, v := range f.UnsupportedFeatures {
+		unsupported[v] = true
+	}
+
+	return Capabilities{
+		ReceiverType:        f.Info.ReceiverType,
+		unsupportedFeatures: unsupported,
+	}
+}
+
+// SubscribeKeyboardFocus records the call and sends the state of the fake's focused text field,
+// then again each time it changes
+func (f *FakeTV) SubscribeKeyboardFocus(ctx context.Context) (<-chan KeyboardEvent, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("SubscribeKeyboardFocus"); err != nil {
+		return nil, err
+	}
+
+	events := make(chan KeyboardEvent)
+	f.subscribe(ctx, fakeSubKeyboard, f.keyboardEvent(),
+		func(v interface{}) bool {
+			select {
+			case events <- v.(KeyboardEvent):
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		},
+		func() {
+			close(events)
+		})
+
+	return events, nil
+}
+
+// SubscribeForegroundApp records the call and sends the fake's current app, then again each time
+// it changes
+func (f *FakeTV) SubscribeForegroundApp(ctx context.Context) (<-chan ForegroundApp, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("SubscribeForegroundApp"); err != nil {
+		return nil, err
+	}
+
+	apps := make(chan ForegroundApp)
+	f.subscribe(ctx, fakeSubForeground, ForegroundApp{AppID: f.CurrentApp},
+		func(v interface{}) bool {
+			select {
+			case apps <- v.(ForegroundApp):
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		},
+		func() {
+			close(apps)
+		})
+
+	return apps, nil
+}
+
+// SubscribeLaunchPoints records the call and sends the fake's launch points. The fake's launch
+// points never change.
+func (f *FakeTV) SubscribeLaunchPoints(ctx context.Context) (<-chan LaunchPointChange, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("SubscribeLaunchPoints"); err != nil {
+		return nil, err
+	}
+
+	launchPoints := make([]LaunchPoint, len(f.LaunchPoints))
+	for i, v := range f.LaunchPoints {
+		v.tv = f
+		launchPoints[i] = v
+	}
+
+	changes := make(chan LaunchPointChange)
+	initial := LaunchPointChange{
+		Type:         LaunchPointsListed,
+		LaunchPoints: launchPoints,
+	}
+	f.subscribe(ctx, fakeSubLaunchPoints, initial,
+		func(v interface{}) bool {
+			select {
+			case changes <- v.(LaunchPointChange):
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		},
+		func() {
+			close(changes)
+		})
+
+	return changes, nil
+}
+
+// SubscribePowerState records the call and sends the fake's power state, then again each time it
+// changes
+func (f *FakeTV) SubscribePowerState(ctx context.Context) (<-chan PowerStatus, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("SubscribePowerState"); err != nil {
+		return nil, err
+	}
+
+	states := make(chan PowerStatus)
+	f.subscribe(ctx, fakeSubPower, f.currentPowerState(),
+		func(v interface{}) bool {
+			select {
+			case states <- v.(PowerStatus):
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		},
+		func() {
+			close(states)
+		})
+
+	return states, nil
+}
+
+// fetchIcon returns the icon for the app or input with the provided ID from the fake's icons
+func (f *FakeTV) fetchIcon(ctx context.Context, id, version, iconURL string) ([]byte, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.record("Icon", id); err != nil {
+		return nil, err
+	}
+
+	icon, ok := f.Icons[id]
+	if !ok {
+		return nil, ErrNoIcon
+	}
+
+	return icon, nil
+}
+
+// record adds the call to the list of calls, and returns the error configured for the method.
+// The lock must be held by the caller.
+func (f *FakeTV) record(method string, args ...interface{}) error {
+	f.calls = append(f.calls, FakeCall{
+		Method: method,
+		Args:   args,
+	})
+
+	return f.Errors[method]
+}
+
+func (f *FakeTV) recordOnly(method string, args ...interface{}) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.record(method, args...)
+}
+
+// sendToAlert sends a button press, or alertClosed, to the open alert. The lock must be held by
+// the caller.
+func (f *FakeTV) sendToAlert(index int) error {
+	open := f.openAlert
+	f.openAlert = nil
+
+	select {
+	case <-open.alert.Done():
+		return ErrFakeNotFound
+	case open.presses <- index:
+		return nil
+	}
+}
+
+// channels returns a copy of the fake's channels, bound to the fake
+func (f *FakeTV) channels() []Channel {
+	channels := make([]Channel, len(f.Channels))
+	for i, v := range f.Channels {
+		v.tv = f
+		channels[i] = v
+	}
+
+	return channels
+}
+
+// inputs returns a copy of the fake's inputs, bound to the fake
+func (f *FakeTV) inputs() []Input {
+	inputs := make([]Input, len(f.Inputs))
+	for i, v := range f.Inputs {
+		v.tv = f
+		inputs[i] = v
+	}
+
+	return inputs
+}
+
+// apps returns a copy of the fake's apps, bound to the fake
+func (f *FakeTV) apps() []App {
+	apps := make([]App, len(f.Apps))
+	for i, v := range f.Apps {
+		v.tv = f
+		apps[i] = v
+	}
+
+	return apps
+}
+
+func (f *FakeTV) currentChannel() (Channel, error) {
+	for _, v := range f.Channels {
+		if v.ChannelNumber == f.CurrentChannel {
+			v.tv = f
+			return v, nil
+		}
+	}
+
+	return Channel{}, ErrFakeNotFound
+}
+
+func (f *FakeTV) stepChannel(step int) {
+	if len(f.Channels) == 0 {
+		return
+	}
+
+	index := 0
+	for i, v := range f.Channels {
+		if v.ChannelNumber == f.CurrentChannel {
+			index = i + step
+			break
+		}
+	}
+
+	index = (index + len(f.Channels)) % len(f.Channels)
+	f.CurrentChannel = f.Channels[index].ChannelNumber
+}
+
+func (f *FakeTV) hasApp(appID string) bool {
+	for _, v := range f.Apps {
+		if v.ID == appID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// launch starts the app in the fake, and returns the ID of the new session
+func (f *FakeTV) launch(appID string) string {
+	f.setCurrentApp(appID)
+	if !f.isRunning(appID) {
+		f.RunningApps = append(f.RunningApps, appID)
+	}
+
+	if f.sessions == nil {
+		f.sessions = make(map[string]string)
+	}
+
+	f.lastSession++
+	sessionID := fmt.Sprintf("fake-session-%v", f.lastSession)
+	f.sessions[sessionID] = appID
+	return sessionID
+}
+
+func (f *FakeTV) close(appID string) {
+	if f.CurrentApp == appID {
+		f.setCurrentApp("")
+	}
+
+	for i, v := range f.RunningApps {
+		if v == appID {
+			f.RunningApps = append(f.RunningApps[:i], f.RunningApps[i+1:]...)
+			break
+		}
+	}
+
+	for k, v := range f.sessions {
+		if v == appID {
+			delete(f.sessions, k)
+		}
+	}
+}
+
+func (f *FakeTV) isRunning(appID string) bool {
+	for _, v := range f.RunningApps {
+		if v == appID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setCurrentApp changes the fake's current app, and tells its subscribers if it's changed
+func (f *FakeTV) setCurrentApp(appID string) {
+	if f.CurrentApp == appID {
+		return
+	}
+
+	f.CurrentApp = appID
+	f.publish(fakeSubForeground, ForegroundApp{AppID: appID})
+}
+
+func (f *FakeTV) keyboardEvent() KeyboardEvent {
+	return KeyboardEvent{
+		Focused:        f.keyboardFocused,
+		FieldType:      FieldText,
+		Text:           f.Text,
+		CursorPosition: len([]rune(f.Text)),
+	}
+}
+
+func (f *FakeTV) currentPowerState() PowerStatus {
+	switch {
+	case f.powerState.State != "":
+		return f.powerState
+	case f.IsOn:
+		return PowerStatus{State: PowerActive}
+	default:
+		return PowerStatus{State: PowerSuspend}
+	}
+}
+
+// subscribe adds a subscriber for the provided kind of change. The initial value is passed to
+// deliver straight away, followed by each change, until deliver returns false or the context is
+// done, at which point finish is called. The lock must be held by the caller.
+func (f *FakeTV) subscribe(ctx context.Context, kind string, initial interface{},
+	deliver func(v interface{}) bool, finish func()) {
+	sub := &fakeSubscriber{
+		kind:  kind,
+		queue: []interface{}{initial},
+		wake:  make(chan struct{}, 1),
+	}
+	f.subscribers = append(f.subscribers, sub)
+
+	go func() {
+		defer finish()
+		defer f.unsubscribe(sub)
+
+		for {
+			sub.lock.Lock()
+			if len(sub.queue) == 0 {
+				sub.lock.Unlock()
+				select {
+				case <-ctx.Done():
+					return
+				case <-sub.wake:
+					continue
+				}
+			}
+
+			v := sub.queue[0]
+			sub.queue = sub.queue[1:]
+			sub.lock.Unlock()
+
+			if !deliver(v) {
+				return
+			}
+		}
+	}()
+}
+
+func (f *FakeTV) unsubscribe(sub *fakeSubscriber) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for i, v := range f.subscribers {
+		if v == sub {
+			f.subscribers = append(f.subscribers[:i], f.subscribers[i+1:]...)
+			break
+		}
+	}
+}
+
+// publish sends a change to the subscribers for its kind. The lock must be held by the caller.
+func (f *FakeTV) publish(kind string, v interface{}) {
+	for _, sub := range f.subscribers {
+		if sub.kind != kind {
+			continue
+		}
+
+		sub.lock.Lock()
+		sub.queue = append(sub.queue, v)
+		sub.lock.Unlock()
+
+		select {
+		case sub.wake <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package control
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Scores given to a name depending on how well it matches a query, from best to worst
+const (
+	matchExact       = 5
+	matchPrefix      = 4
+	matchWordPrefix  = 3
+	matchSubstring   = 2
+	matchSubsequence = 1
+	matchNone        = 0
+)
+
+// ErrNoMatch is returned when nothing matches a name being looked up
+var ErrNoMatch = errors.New("Nothing matches the name")
+
+// ErrAmbiguousMatch is returned when more than one thing matches a name being looked up equally well
+var ErrAmbiguousMatch = errors.New("More than one thing matches the name")
+
+// accentFolds maps accented latin letters to the letters they're based on
+var accentFolds = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ą': "a",
+	'æ': "ae", 'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'ł': "l",
+	'ñ': "n", 'ń': "n", 'ň': "n", 'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o",
+	'œ': "oe", 'ř': "r", 'ś': "s", 'š': "s", 'ß': "ss", 'ť': "t", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+// FindApp looks up the apps shown in the TV's launcher by name. Matching ignores case, accents and
+// punctuation, and allows for partial names, so "netflix" and "Netf" both match "Netflix". Matches
+// are returned best first.
+//
+// ErrNoMatch is returned if no apps match. If more than one app matches equally well, the matches
+// are still returned, along with ErrAmbiguousMatch.
+func (tv *LgTv) FindApp(query string) ([]App, error) {
+	apps, err := tv.ListInstalledApps(VisibleOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	return findApps(apps, query)
+}
+
+// FindInput looks up the TV's external inputs by label or ID, in the same way as FindApp
+func (tv *LgTv) FindInput(query string) ([]Input, error) {
+	inputs, err := tv.ListExternalInputs()
+	if err != nil {
+		return nil, err
+	}
+
+	return findInputs(inputs, query)
+}
+
+// FindChannel looks up the TV's channels by name or number, in the same way as FindApp
+func (tv *LgTv) FindChannel(query string) ([]Channel, error) {
+	channels, err := tv.ListChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	return findChannels(channels, query)
+}
+
+// LaunchAppByName launches the app which best matches the provided name, as found by FindApp.
+// It returns the ID of the new session.
+func (tv *LgTv) LaunchAppByName(name string) (string, error) {
+	apps, err := tv.FindApp(name)
+	if err != nil {
+		return "", err
+	}
+
+	return tv.LaunchApp(apps[0].ID)
+}
+
+// SwitchInputByLabel switches to the input which best matches the provided label, as found by FindInput
+func (tv *LgTv) SwitchInputByLabel(label string) error {
+	inputs, err := tv.FindInput(label)
+	if err != nil {
+		return err
+	}
+
+	return tv.SwitchInput(inputs[0].ID)
+}
+
+func findApps(apps []App, query string) ([]App, error) {
+	scores := make([]int, len(apps))
+	for i, v := range apps {
+		scores[i] = matchScore(query, v.Name)
+	}
+
+	var matches []App
+	err := rankMatches(scores, func(i int) {
+		matches = append(matches, apps[i])
+	})
+	return matches, err
+}
+
+func findInputs(inputs []Input, query string) ([]Input, error) {
+	scores := make([]int, len(inputs))
+	for i, v := range inputs {
+		scores[i] = matchScore(query, v.Label, v.ID)
+	}
+
+	var matches []Input
+	err := rankMatches(scores, func(i int) {
+		matches = append(matches, inputs[i])
+	})
+	return matches, err
+}
+
+func findChannels(channels []Channel, query string) ([]Channel, error) {
+	scores := make([]int, len(channels))
+	for i, v := range channels {
+		scores[i] = matchScore(query, v.ChannelName, strconv.Itoa(v.ChannelNumber))
+	}
+
+	var matches []Channel
+	err := rankMatches(scores, func(i int) {
+		matches = append(matches, channels[i])
+	})
+	return matches, err
+}
+
+// rankMatches calls add with the index of each non-zero score, from the highest score to the lowest.
+// Equal scores keep their original order. It returns ErrNoMatch if there are no matches, or
+// ErrAmbiguousMatch if the best score is shared by more than one match.
+func rankMatches(scores []int, add func(i int)) error {
+	var indexes []int
+	for i, v := range scores {
+		if v > matchNone {
+			indexes = append(indexes, i)
+		}
+	}
+
+	if len(indexes) == 0 {
+		return ErrNoMatch
+	}
+
+	sort.SliceStable(indexes, func(a, b int) bool {
+		return scores[indexes[a]] > scores[indexes[b]]
+	})
+	for _, v := range indexes {
+		add(v)
+	}
+
+	if len(indexes) > 1 && scores[indexes[0]] == scores[indexes[1]] {
+		return ErrAmbiguousMatch
+	}
+
+	return nil
+}
+
+// matchScore returns how well the query matches the best of the provided names
+func matchScore(query string, names ...string) int {
+	queryWords := normaliseName(query)
+	if len(queryWords) == 0 {
+		return matchNone
+	}
+	compactQuery := strings.Join(queryWords, "")
+
+	best := matchNone
+	for _, v := range names {
+		words := normaliseName(v)
+		compact := strings.Join(words, "")
+
+		score := matchNone
+		switch {
+		case compact == "":
+		case compact == compactQuery:
+			score = matchExact
+		case strings.HasPrefix(compact, compactQuery):
+			score = matchPrefix
+		case hasWordPrefixes(words, queryWords):
+			score = matchWordPrefix
+		case strings.Contains(compact, compactQuery):
+			score = matchSubstring
+		case isSubsequence(compact, compactQuery):
+			score = matchSubsequence
+		}
+
+		if score > best {
+			best = score
+		}
+	}
+
+	return best
+}
+
+// normaliseName lower cases a name, folds accented letters, and splits it in to words of letters
+// and digits, dropping punctuation
+func normaliseName(name string) []string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if folded, ok := accentFolds[r]; ok {
+			b.WriteString(folded)
+		} else if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+
+	return strings.Fields(b.String())
+}
+
+// hasWordPrefixes returns whether each of the query words is the start of a word in the name, in order
+func hasWordPrefixes(words, queryWords []string) bool {
+	i := 0
+	for _, v := range words {
+		if i < len(queryWords) && strings.HasPrefix(v, queryWords[i]) {
+			i++
+		}
+	}
+
+	return i == len(queryWords)
+}
+
+// isSubsequence returns whether all of the characters in the query appear in s, in order
+func isSubsequence(s, query string) bool {
+	queryRunes := []rune(query)
+	i := 0
+	for _, r := range s {
+		if i < len(queryRunes) && r == queryRunes[i] {
+			i++
+		}
+	}
+
+	return i == len(queryRunes)
+}
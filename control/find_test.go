@@ -0,0 +1,143 @@
+package control
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchScore(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		names []string
+		want  int
+	}{
+		{"exact", "netflix", []string{"Netflix"}, matchExact},
+		{"exact ignoring punctuation", "disney plus", []string{"Disney+ Plus"}, matchExact},
+		{"exact ignoring accents", "deja vu", []string{"Déjà Vu"}, matchExact},
+		{"prefix", "netf", []string{"Netflix"}, matchPrefix},
+		{"word prefixes", "prime vid", []string{"Amazon Prime Video"}, matchWordPrefix},
+		{"substring", "flix", []string{"Netflix"}, matchSubstring},
+		{"subsequence", "ntfx", []string{"Netflix"}, matchSubsequence},
+		{"no match", "hulu", []string{"Netflix"}, matchNone},
+		{"empty query", "", []string{"Netflix"}, matchNone},
+		{"punctuation query", "+", []string{"Disney+"}, matchNone},
+		{"best of several names", "hdmi1", []string{"Games Console", "HDMI_1"}, matchExact},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchScore(tt.query, tt.names...); got != tt.want {
+				t.Errorf("Expected score %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFindApps(t *testing.T) {
+	tests := []struct {
+		name    string
+		apps    []string
+		query   string
+		want    []string
+		wantErr error
+	}{
+		{
+			name:  "exact beats prefix",
+			apps:  []string{"YouTube Kids", "YouTube"},
+			query: "youtube",
+			want:  []string{"YouTube", "YouTube Kids"},
+		},
+		{
+			name:  "prefix beats substring",
+			apps:  []string{"My Netflix", "Netflix Kids"},
+			query: "netflix",
+			want:  []string{"Netflix Kids", "My Netflix"},
+		},
+		{
+			name:    "tied prefixes are ambiguous",
+			apps:    []string{"YouTube Kids", "YouTube Music", "Netflix"},
+			query:   "youtube",
+			want:    []string{"YouTube Kids", "YouTube Music"},
+			wantErr: ErrAmbiguousMatch,
+		},
+		{
+			name:    "tied exact matches are ambiguous",
+			apps:    []string{"Netflix", "NETFLIX"},
+			query:   "netflix",
+			want:    []string{"Netflix", "NETFLIX"},
+			wantErr: ErrAmbiguousMatch,
+		},
+		{
+			name:  "tie below the best match isn't ambiguous",
+			apps:  []string{"YouTube Kids", "YouTube", "YouTube Music"},
+			query: "youtube",
+			want:  []string{"YouTube", "YouTube Kids", "YouTube Music"},
+		},
+		{
+			name:    "no match",
+			apps:    []string{"Netflix", "YouTube"},
+			query:   "hulu",
+			wantErr: ErrNoMatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apps := make([]App, len(tt.apps))
+			for i, v := range tt.apps {
+				apps[i] = App{Name: v, ID: v}
+			}
+
+			matches, err := findApps(apps, tt.query)
+			if err != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+
+			var got []string
+			for _, v := range matches {
+				got = append(got, v.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected matches %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFindChannels(t *testing.T) {
+	channels := []Channel{
+		{ChannelName: "BBC One", ChannelNumber: 1},
+		{ChannelName: "BBC Two", ChannelNumber: 2},
+		{ChannelName: "ITV", ChannelNumber: 10},
+	}
+
+	matches, err := findChannels(channels, "1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if matches[0].ChannelNumber != 1 {
+		t.Errorf("Expected channel 1 to match best, got %v", matches[0].ChannelNumber)
+	}
+
+	_, err = findChannels(channels, "bbc")
+	if err != ErrAmbiguousMatch {
+		t.Errorf("Expected ErrAmbiguousMatch, got %v", err)
+	}
+}
+
+func TestFakeFindAppVisibleOnly(t *testing.T) {
+	tv := NewFakeTV()
+	tv.Apps = []App{
+		{Name: "YouTube", ID: "youtube.leanback.v4", Visible: true},
+		{Name: "YouTube Updater", ID: "youtube.updater"},
+	}
+
+	matches, err := tv.FindApp("youtube")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(matches) != 1 || matches[0].ID != "youtube.leanback.v4" {
+		t.Errorf("Expected only the visible app to match, got %v", matches)
+	}
+}
@@ -0,0 +1,162 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dhickie/go-lgtv/connection"
+)
+
+const (
+	hdmiAppIDPrefix         = "com.webos.app.hdmi"
+	defaultAppLaunchTimeout = 30 * time.Second
+)
+
+// ErrAppLaunchTimeout is returned when an app being launched doesn't come to the foreground in time
+var ErrAppLaunchTimeout = errors.New("Timeout waiting for launched app to come to the foreground")
+
+// ErrAppReplaced is returned when a different app comes to the foreground while waiting for an app
+// being launched
+var ErrAppReplaced = errors.New("Another app came to the foreground")
+
+// ForegroundApp is the app currently in the foreground on the TV
+type ForegroundApp struct {
+	AppID     string
+	WindowID  string
+	ProcessID string
+}
+
+// InputID returns the ID of the external input being shown, if the foreground app is one of the
+// TV's HDMI input apps. For example, "com.webos.app.hdmi1" is the app for "HDMI_1".
+func (app ForegroundApp) InputID() (string, bool) {
+	if !strings.HasPrefix(app.AppID, hdmiAppIDPrefix) {
+		return "", false
+	}
+
+	return "HDMI_" + strings.TrimPrefix(app.AppID, hdmiAppIDPrefix), true
+}
+
+// GetForegroundApp returns the app currently in the foreground on the TV
+func (tv *LgTv) GetForegroundApp() (ForegroundApp, error) {
+	var respPayload connection.GetForegroundAppInfoResponsePayload
+	err := tv.doRequest(uriGetForegroundAppInfo, nil, &respPayload)
+	if err != nil {
+		return ForegroundApp{}, err
+	}
+
+	return convertForegroundApp(respPayload), nil
+}
+
+// SubscribeForegroundApp subscribes to changes to the app in the foreground on the TV. The
+// current foreground app is sent straight away, then again each time it changes. The returned
+// channel is closed once the context is done, or the connection to the TV is lost.
+func (tv *LgTv) SubscribeForegroundApp(ctx context.Context) (<-chan ForegroundApp, error) {
+	apps := make(chan ForegroundApp)
+	err := tv.subscribe(ctx, uriGetForegroundAppInfo, nil,
+		func() interface{} {
+			return &connection.GetForegroundAppInfoResponsePayload{}
+		},
+		func(payload interface{}) bool {
+			app := convertForegroundApp(*payload.(*connection.GetForegroundAppInfoResponsePayload))
+			select {
+			case apps <- app:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		},
+		func() {
+			close(apps)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return apps, nil
+}
+
+// LaunchAppAndWait launches the app with the provided ID, and waits for it to come to the foreground
+// on the TV. It returns the ID of the new session.
+//
+// If the context has no deadline, it gives up after 30 seconds. ErrAppLaunchTimeout is returned if
+// the app doesn't come to the foreground before the deadline, and ErrAppReplaced is returned if a
+// different app comes to the foreground instead. If the app was launched, its session ID is returned
+// along with any error from waiting for it, so that the session can still be closed.
+func (tv *LgTv) LaunchAppAndWait(ctx context.Context, appID string) (string, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultAppLaunchTimeout)
+		defer cancel()
+	}
+
+	// Subscribe before launching, so that the app coming to the foreground can't be missed
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	changes, err := tv.SubscribeForegroundApp(subCtx)
+	if err != nil {
+		return "", err
+	}
+	previous, ok := <-changes
+	if !ok {
+		return "", launchWaitError(ctx)
+	}
+
+	sessionID, err := tv.LaunchApp(appID)
+	if err != nil {
+		return "", err
+	}
+
+	return sessionID, waitForLaunch(ctx, changes, previous.AppID, appID)
+}
+
+// waitForLaunch waits for changes to the foreground app to show that the app being launched has
+// come to the foreground, having previously been showing the app with the provided ID
+func waitForLaunch(ctx context.Context, changes <-chan ForegroundApp, previousID, appID string) error {
+	if previousID == appID {
+		return nil
+	}
+
+	for {
+		select {
+		case app, ok := <-changes:
+			if !ok {
+				return launchWaitError(ctx)
+			}
+
+			// The TV briefly reports no foreground app, or the previous one again, while switching
+			switch app.AppID {
+			case appID:
+				return nil
+			case "", previousID:
+			default:
+				return fmt.Errorf("%w: %v", ErrAppReplaced, app.AppID)
+			}
+		case <-ctx.Done():
+			return launchWaitError(ctx)
+		}
+	}
+}
+
+// launchWaitError returns the error for the foreground app subscription finishing while waiting for
+// an app to launch, which is either because the context is done or the connection to the TV was lost
+func launchWaitError(ctx context.Context) error {
+	switch ctx.Err() {
+	case nil:
+		return connection.ErrConnectionClosed
+	case context.DeadlineExceeded:
+		return ErrAppLaunchTimeout
+	default:
+		return ctx.Err()
+	}
+}
+
+func convertForegroundApp(payload connection.GetForegroundAppInfoResponsePayload) ForegroundApp {
+	return ForegroundApp{
+		AppID:     payload.AppID,
+		WindowID:  payload.WindowID,
+		ProcessID: payload.ProcessID,
+	}
+}
@@ -0,0 +1,47 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dhickie/go-lgtv/connection"
+)
+
+func TestWaitForLaunch(t *testing.T) {
+	tests := []struct {
+		name       string
+		previousID string
+		changes    []string
+		closed     bool
+		wantErr    error
+	}{
+		{"already in the foreground", "netflix", nil, false, nil},
+		{"comes to the foreground", "youtube", []string{"netflix"}, false, nil},
+		{"switching", "youtube", []string{"", "youtube", "netflix"}, false, nil},
+		{"replaced", "youtube", []string{"", "com.webos.app.hdmi1"}, false, ErrAppReplaced},
+		{"connection lost", "youtube", []string{""}, true, connection.ErrConnectionClosed},
+		{"timed out", "youtube", []string{""}, false, ErrAppLaunchTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			changes := make(chan ForegroundApp, len(tt.changes))
+			for _, v := range tt.changes {
+				changes <- ForegroundApp{AppID: v}
+			}
+			if tt.closed {
+				close(changes)
+			}
+
+			err := waitForLaunch(ctx, changes, tt.previousID, "netflix")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package control
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const iconPort = 3000
+
+// ErrNoIcon is returned when asking for the icon of an app or input which doesn't have one
+var ErrNoIcon = errors.New("No icon available")
+
+// iconFetcher is implemented by anything which can download icons for apps and inputs
+type iconFetcher interface {
+	fetchIcon(ctx context.Context, id, version, iconURL string) ([]byte, error)
+}
+
+// SetIconCache sets the directory icons downloaded from the TV are cached in. Icons for apps are
+// cached by app ID and version, so a new icon is downloaded when an app is updated. Icons for
+// inputs are cached by input ID and icon URL. An empty directory turns off caching, which is
+// the default. If an icon can't be written to the cache, it's still returned, just not cached.
+func (tv *LgTv) SetIconCache(dir string) {
+	tv.stateLock.Lock()
+	defer tv.stateLock.Unlock()
+
+	tv.iconCacheDir = dir
+}
+
+func (tv *LgTv) fetchIcon(ctx context.Context, id, version, iconURL string) ([]byte, error) {
+	if iconURL == "" {
+		return nil, ErrNoIcon
+	}
+
+	tv.stateLock.Lock()
+	cacheDir := tv.iconCacheDir
+	tv.stateLock.Unlock()
+
+	// Inputs don't have a version, so use the icon URL instead, which changes with the input's icon
+	if version == "" {
+		version = iconURL
+	}
+
+	var cachePath string
+	if cacheDir != "" {
+		cachePath = filepath.Join(cacheDir, iconCacheKey(id, version))
+		data, err := os.ReadFile(cachePath)
+		if err == nil {
+			return data, nil
+		}
+	}
+
+	data, err := tv.downloadIcon(ctx, iconURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// The cache is only an optimisation, so failing to write to it doesn't fail the download
+	if cachePath != "" {
+		writeIconCache(cacheDir, cachePath, data)
+	}
+
+	return data, nil
+}
+
+// downloadIcon downloads the icon at the provided URL from the TV's HTTP server. The host in the
+// URL is replaced with the TV's own address, since the TV doesn't always report one which the
+// client can reach.
+func (tv *LgTv) downloadIcon(ctx context.Context, iconURL string) ([]byte, error) {
+	u, err := url.Parse(iconURL)
+	if err != nil {
+		return nil, err
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = strconv.Itoa(iconPort)
+	}
+	if u.Scheme == "" {
+		u.Scheme = "http"
+	}
+	u.Host = net.JoinHostPort(tv.ip.String(), port)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Failed to download icon: %v", resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func iconCacheKey(id, version string) string {
+	hash := sha256.Sum256([]byte(id + "\x00" + version))
+	return hex.EncodeToString(hash[:])
+}
+
+// writeIconCache writes an icon to the cache. It's written to a temporary file first so that a
+// partly written icon is never read back.
+func writeIconCache(cacheDir, cachePath string, data []byte) error {
+	err := os.MkdirAll(cacheDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(cacheDir, "icon-*")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), cachePath)
+}
@@ -0,0 +1,69 @@
+package control
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFetchIconCache(t *testing.T) {
+	icon := []byte("icon data")
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		w.Write(icon)
+	}))
+	defer server.Close()
+
+	// The TV reports icon URLs with a host the client may not be able to reach, which is replaced
+	// with the TV's own address
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iconURL := "http://unreachable:" + serverURL.Port() + "/icon.png"
+
+	// A file where the cache directory should be, so that the cache can't be written
+	blocked := filepath.Join(t.TempDir(), "blocked")
+	if err := os.WriteFile(blocked, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		cacheDir      string
+		wantDownloads int
+	}{
+		{"no cache", "", 2},
+		{"cache", filepath.Join(t.TempDir(), "icons"), 1},
+		{"cache can't be written", filepath.Join(blocked, "icons"), 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			downloads = 0
+			tv := &LgTv{ip: net.ParseIP("127.0.0.1")}
+			tv.SetIconCache(tt.cacheDir)
+
+			for i := 0; i < 2; i++ {
+				data, err := tv.fetchIcon(context.Background(), "netflix", "1.0", iconURL)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if !bytes.Equal(data, icon) {
+					t.Errorf("Expected icon %q, got %q", icon, data)
+				}
+			}
+
+			if downloads != tt.wantDownloads {
+				t.Errorf("Expected %v downloads, got %v", tt.wantDownloads, downloads)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package control
+
+import (
+	"net"
+	"strings"
+
+	"github.com/dhickie/go-lgtv/connection"
+)
+
+// TVInfo describes the TV's hardware and software
+type TVInfo struct {
+	// ModelName is the TV's model, for example "OLED55C7V"
+	ModelName string
+	// ReceiverType is the type of broadcast the TV's tuner receives, for example "dvb" or "atsc"
+	ReceiverType string
+	// Features are the optional features of the TV, such as "3d" and "dvr", and whether it has them
+	Features map[string]bool
+	// ProductName is the name of the TV's software, for example "webOSTV 3.5"
+	ProductName string
+	// WebOSVersion is the version of webOS the TV is running, for example "3.5"
+	WebOSVersion string
+	// Firmware is the version of the TV's firmware, for example "05.70.20"
+	Firmware string
+	// Platform is the name of the TV's hardware platform, for example "HE_DTV_W17O_AFADABAA"
+	Platform     string
+	Country      string
+	LanguageCode string
+	DeviceID     string
+	// MAC is the MAC address of the TV, if it could be worked out from the device ID
+	MAC net.HardwareAddr
+}
+
+// GetInfo returns information about the TV's model and the software it's running
+func (tv *LgTv) GetInfo() (TVInfo, error) {
+	var systemPayload connection.GetSystemInfoResponsePayload
+	var softwarePayload connection.GetSoftwareInfoResponsePayload
+	errs := tv.doBatch([]connection.BatchRequest{
+		{URI: uriGetSystemInfo, Response: &systemPayload},
+		{URI: uriGetSoftwareInfo, Response: &softwarePayload},
+	})
+	for _, v := range errs {
+		if v != nil {
+			return TVInfo{}, v
+		}
+	}
+
+	return convertInfo(systemPayload, softwarePayload), nil
+}
+
+func convertInfo(system connection.GetSystemInfoResponsePayload, software connection.GetSoftwareInfoResponsePayload) TVInfo {
+	info := TVInfo{
+		ModelName:    system.ModelName,
+		ReceiverType: system.ReceiverType,
+		Features:     system.Features,
+		ProductName:  software.ProductName,
+		Platform:     software.ModelName,
+		Country:      software.Country,
+		LanguageCode: software.LanguageCode,
+		DeviceID:     software.DeviceID,
+	}
+
+	// The product name is the name of the OS followed by its version, like "webOSTV 3.5"
+	if i := strings.LastIndex(software.ProductName, " "); i >= 0 {
+		info.WebOSVersion = software.ProductName[i+1:]
+	}
+
+	if software.MajorVersion != "" {
+		info.Firmware = software.MajorVersion
+		if software.MinorVersion != "" {
+			info.Firmware += "." + software.MinorVersion
+		}
+	}
+
+	mac, err := net.ParseMAC(software.DeviceID)
+	if err == nil {
+		info.MAC = mac
+	}
+
+	return info
+}
@@ -1,13 +1,26 @@
 package control
 
+import "context"
+
 // Input represents an external input to the TV
 type Input struct {
-	Label string
-	ID    string
-	tv    *LgTv
+	Label   string
+	ID      string
+	iconURL string
+	tv      Inputs
 }
 
 // Switch switches the TV to this input
 func (i *Input) Switch() error {
 	return i.tv.SwitchInput(i.ID)
 }
+
+// Icon downloads the input's icon from the TV
+func (i *Input) Icon(ctx context.Context) ([]byte, error) {
+	fetcher, ok := i.tv.(iconFetcher)
+	if !ok {
+		return nil, ErrNoIcon
+	}
+
+	return fetcher.fetchIcon(ctx, i.ID, "", i.iconURL)
+}
@@ -0,0 +1,139 @@
+package control
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dhickie/go-lgtv/connection"
+)
+
+// FieldType is the type of content a text field on the TV accepts
+type FieldType string
+
+// Types of text field on the TV
+const (
+	FieldText     FieldType = "text"
+	FieldPassword FieldType = "password"
+	FieldNumber   FieldType = "number"
+	FieldEmail    FieldType = "email"
+	FieldURL      FieldType = "url"
+	FieldPhone    FieldType = "phonenumber"
+)
+
+// KeyboardEvent is sent when a text field on the TV gains or loses focus, or its content changes
+type KeyboardEvent struct {
+	Focused        bool
+	FieldType      FieldType
+	Text           string
+	CursorPosition int
+}
+
+// TypeTextOptions are the options used when typing text in to the TV
+type TypeTextOptions struct {
+	// Replace replaces the existing content of the focused field, instead of adding to it
+	Replace bool
+	// Submit presses enter once the text has been typed
+	Submit bool
+}
+
+// InsertText inserts text in to the currently focused text field on the TV. If replace
+// is true, the existing content of the field is replaced.
+func (tv *LgTv) InsertText(text string, replace bool) error {
+	payload := connection.InsertTextPayload{
+		Text:    text,
+		Replace: replace,
+	}
+	return tv.doRequest(uriInsertText, payload, nil)
+}
+
+// DeleteCharacters deletes the specified number of characters before the cursor in the
+// currently focused text field on the TV
+func (tv *LgTv) DeleteCharacters(count int) error {
+	payload := connection.DeleteCharactersPayload{
+		Count: count,
+	}
+	return tv.doRequest(uriDeleteCharacters, payload, nil)
+}
+
+// SendEnterKey presses enter in the currently focused text field on the TV
+func (tv *LgTv) SendEnterKey() error {
+	return tv.doRequest(uriSendEnterKey, nil, nil)
+}
+
+// TypeText types a whole string in to the currently focused text field on the TV, using the
+// provided options, which can be nil. Any new lines in the text press enter.
+func (tv *LgTv) TypeText(text string, opts *TypeTextOptions) error {
+	if opts == nil {
+		opts = &TypeTextOptions{}
+	}
+
+	lines := strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n")
+	for i, v := range lines {
+		if i > 0 {
+			err := tv.SendEnterKey()
+			if err != nil {
+				return err
+			}
+		}
+
+		// Only the first line can replace the content of the field, otherwise each
+		// line would replace the one before it
+		replace := opts.Replace && i == 0
+		if v != "" || replace {
+			err := tv.InsertText(v, replace)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if opts.Submit {
+		return tv.SendEnterKey()
+	}
+
+	return nil
+}
+
+// SubscribeKeyboardFocus subscribes to changes to the focused text field on the TV. An event is sent
+// straight away with the current state, then each time a text field gains or loses focus, or its
+// content changes. The returned channel is closed once the context is done, or the connection to
+// the TV is lost.
+//
+// Note that while subscribed, the TV treats the client as a remote keyboard, and may not show its
+// own on-screen keyboard.
+func (tv *LgTv) SubscribeKeyboardFocus(ctx context.Context) (<-chan KeyboardEvent, error) {
+	events := make(chan KeyboardEvent)
+	err := tv.subscribe(ctx, uriRegisterKeyboard, nil,
+		func() interface{} {
+			return &connection.RegisterRemoteKeyboardResponsePayload{}
+		},
+		func(payload interface{}) bool {
+			widget := payload.(*connection.RegisterRemoteKeyboardResponsePayload).CurrentWidget
+			fieldType := FieldType(widget.ContentType)
+			if widget.HiddenText {
+				fieldType = FieldPassword
+			}
+
+			event := KeyboardEvent{
+				Focused:        widget.Focus,
+				FieldType:      fieldType,
+				Text:           widget.SurroundingText,
+				CursorPosition: widget.CursorPosition,
+			}
+
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		},
+		func() {
+			close(events)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -0,0 +1,134 @@
+package control
+
+import (
+	"context"
+
+	"github.com/dhickie/go-lgtv/connection"
+)
+
+// LaunchPointChangeType is the type of change made to the launch points on the TV's home screen
+type LaunchPointChangeType string
+
+// Types of change made to launch points
+const (
+	// LaunchPointsListed is the first change sent when subscribing, and holds every launch point
+	LaunchPointsListed LaunchPointChangeType = "listed"
+	LaunchPointAdded   LaunchPointChangeType = "added"
+	LaunchPointRemoved LaunchPointChangeType = "removed"
+	LaunchPointUpdated LaunchPointChangeType = "updated"
+	LaunchPointMoved   LaunchPointChangeType = "moved"
+)
+
+// LaunchPoint is an entry on the TV's home screen ribbon. As well as apps, launch points can
+// be inputs, channels or bookmarks, each of which has its own title and icon.
+type LaunchPoint struct {
+	ID              string
+	AppID           string
+	Title           string
+	Icon            string
+	LargeIcon       string
+	BackgroundColor string
+	BackgroundImage string
+	IconColor       string
+	Params          map[string]interface{}
+	Removable       bool
+	SystemApp       bool
+	tv              Apps
+}
+
+// LaunchPointChange is a change to the launch points on the TV's home screen
+type LaunchPointChange struct {
+	Type LaunchPointChangeType
+	// LaunchPoint is the launch point which changed. It's not set for LaunchPointsListed.
+	LaunchPoint LaunchPoint
+	// Position is the new position of the launch point on the ribbon, if it's been added or moved
+	Position int
+	// LaunchPoints holds every launch point, in ribbon order. It's only set for LaunchPointsListed.
+	LaunchPoints []LaunchPoint
+}
+
+// Launch launches the launch point. It returns the ID of the new session
+func (lp *LaunchPoint) Launch() (string, error) {
+	return lp.tv.LaunchAppWithParams(lp.AppID, "", lp.Params)
+}
+
+// ListLaunchPoints lists the launch points on the TV's home screen ribbon, in the order they're shown
+func (tv *LgTv) ListLaunchPoints() ([]LaunchPoint, error) {
+	var respPayload connection.ListLaunchPointsResponsePayload
+	err := tv.doRequest(uriListLaunchPoints, nil, &respPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	return tv.convertLaunchPointList(respPayload.LaunchPoints), nil
+}
+
+// SubscribeLaunchPoints subscribes to changes to the launch points on the TV's home screen ribbon.
+// A LaunchPointsListed change holding every launch point is sent straight away, followed by a
+// change each time one is added, removed, updated or moved. The returned channel is closed once
+// the context is done, or the connection to the TV is lost.
+func (tv *LgTv) SubscribeLaunchPoints(ctx context.Context) (<-chan LaunchPointChange, error) {
+	changes := make(chan LaunchPointChange)
+	err := tv.subscribe(ctx, uriListLaunchPoints, nil,
+		func() interface{} {
+			return &connection.ListLaunchPointsResponsePayload{}
+		},
+		func(payload interface{}) bool {
+			p := payload.(*connection.ListLaunchPointsResponsePayload)
+
+			var change LaunchPointChange
+			if p.Change == "" {
+				change = LaunchPointChange{
+					Type:         LaunchPointsListed,
+					LaunchPoints: tv.convertLaunchPointList(p.LaunchPoints),
+				}
+			} else {
+				change = LaunchPointChange{
+					Type:        LaunchPointChangeType(p.Change),
+					LaunchPoint: tv.convertLaunchPoint(p.LaunchPoint),
+					Position:    p.Position,
+				}
+			}
+
+			select {
+			case changes <- change:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		},
+		func() {
+			close(changes)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+func (tv *LgTv) convertLaunchPointList(payload []connection.LaunchPoint) []LaunchPoint {
+	launchPoints := make([]LaunchPoint, len(payload))
+	for i, v := range payload {
+		launchPoints[i] = tv.convertLaunchPoint(v)
+	}
+
+	return launchPoints
+}
+
+func (tv *LgTv) convertLaunchPoint(payload connection.LaunchPoint) LaunchPoint {
+	return LaunchPoint{
+		ID:              payload.LaunchPointID,
+		AppID:           payload.ID,
+		Title:           payload.Title,
+		Icon:            payload.Icon,
+		LargeIcon:       payload.LargeIcon,
+		BackgroundColor: payload.BgColor,
+		BackgroundImage: payload.BgImage,
+		IconColor:       payload.IconColor,
+		Params:          payload.Params,
+		Removable:       payload.Removable,
+		SystemApp:       payload.SystemApp,
+		tv:              tv,
+	}
+}
@@ -0,0 +1,304 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMacroKeyDelay    = 150 * time.Millisecond
+	defaultMacroAppTimeout  = 10 * time.Second
+	macroAppPollInterval    = 250 * time.Millisecond
+	macroMaxKeyRepeat       = 100
+	macroStepKindKey        = "key"
+	macroStepKindWait       = "wait"
+	macroStepKindText       = "text"
+	macroStepKindWaitForApp = "app"
+)
+
+// ErrInvalidMacro is returned when a macro can't be parsed
+var ErrInvalidMacro = errors.New("Invalid macro")
+
+// ErrMacroAppTimeout is returned when a macro times out waiting for an app to come to the foreground
+var ErrMacroAppTimeout = errors.New("Timeout waiting for app to come to the foreground")
+
+// MacroError is returned when running a step of a macro fails
+type MacroError struct {
+	// Index is the index of the step which failed, starting from 0
+	Index int
+	// Step is the text of the step which failed
+	Step string
+	// Err is the reason the step failed
+	Err error
+}
+
+func (e *MacroError) Error() string {
+	return fmt.Sprintf("Macro step %v (%v) failed: %v", e.Index+1, e.Step, e.Err)
+}
+
+// Unwrap returns the reason the step failed
+func (e *MacroError) Unwrap() error {
+	return e.Err
+}
+
+// MacroTarget is implemented by anything a macro can be run against. It is satisfied by LgTv, and
+// by FakeTV for use in tests.
+type MacroTarget interface {
+	PressKeys(keys ...Key) error
+	InsertText(text string, replace bool) error
+	GetForegroundApp() (ForegroundApp, error)
+}
+
+var (
+	_ MacroTarget = (*LgTv)(nil)
+	_ MacroTarget = (*FakeTV)(nil)
+)
+
+// Macro is a sequence of steps to run against the TV, such as pressing remote control keys,
+// waiting and typing text. It's parsed from a compact format using ParseMacro.
+type Macro struct {
+	// KeyDelay is the time to wait after each key press, to give the TV time to respond to it
+	KeyDelay time.Duration
+	steps    []macroStep
+}
+
+type macroStep struct {
+	source   string
+	kind     string
+	key      Key
+	count    int
+	duration time.Duration
+	text     string
+	appID    string
+}
+
+// ParseMacro parses a macro. Steps are separated by commas, semicolons or new lines, and
+// can be any of the following:
+//
+//	HOME              Press a remote control key, by name (case insensitive)
+//	DOWN*3            Press a key several times
+//	wait 500ms        Wait for a duration
+//	text "Netflix"    Insert text in to the focused text field
+//	app netflix 20s   Wait for an app to be in the foreground, with an optional timeout (10s by default)
+//
+// For example: "HOME, wait 1s, RIGHT*2, ENTER, app com.webos.app.settings, DOWN, ENTER"
+func ParseMacro(s string) (*Macro, error) {
+	sources, err := splitMacro(s)
+	if err != nil {
+		return nil, err
+	}
+
+	macro := &Macro{
+		KeyDelay: defaultMacroKeyDelay,
+	}
+	for _, v := range sources {
+		step, err := parseMacroStep(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidMacro, v, err)
+		}
+
+		macro.steps = append(macro.steps, step)
+	}
+
+	return macro, nil
+}
+
+// Len returns the number of steps in the macro
+func (m *Macro) Len() int {
+	return len(m.steps)
+}
+
+// Run runs the macro against the TV. It stops as soon as the context is done or a step fails,
+// in which case a *MacroError is returned describing the step.
+func (m *Macro) Run(ctx context.Context, tv MacroTarget) error {
+	for i, v := range m.steps {
+		err := m.runStep(ctx, tv, v)
+		if err != nil {
+			return &MacroError{
+				Index: i,
+				Step:  v.source,
+				Err:   err,
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m *Macro) runStep(ctx context.Context, tv MacroTarget, step macroStep) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	switch step.kind {
+	case macroStepKindKey:
+		for i := 0; i < step.count; i++ {
+			err := tv.PressKeys(step.key)
+			if err != nil {
+				return err
+			}
+
+			err = sleepContext(ctx, m.KeyDelay)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case macroStepKindWait:
+		return sleepContext(ctx, step.duration)
+	case macroStepKindText:
+		return tv.InsertText(step.text, false)
+	default:
+		return waitForApp(ctx, tv, step.appID, step.duration)
+	}
+}
+
+// splitMacro splits a macro in to the source of its steps, leaving separators inside quotes alone
+func splitMacro(s string) ([]string, error) {
+	var sources []string
+	var current strings.Builder
+	inQuotes := false
+	escaped := false
+
+	add := func() {
+		source := strings.TrimSpace(current.String())
+		if source != "" {
+			sources = append(sources, source)
+		}
+		current.Reset()
+	}
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case inQuotes && r == '\\':
+			escaped = true
+		case r == '"':
+			inQuotes = !inQuotes
+		case !inQuotes && (r == ',' || r == ';' || r == '\n'):
+			add()
+			continue
+		}
+
+		current.WriteRune(r)
+	}
+
+	if inQuotes {
+		return nil, fmt.Errorf("%w: unterminated quote", ErrInvalidMacro)
+	}
+	add()
+
+	return sources, nil
+}
+
+func parseMacroStep(source string) (macroStep, error) {
+	step := macroStep{
+		source: source,
+	}
+
+	fields := strings.Fields(source)
+	switch strings.ToLower(fields[0]) {
+	case macroStepKindWait:
+		if len(fields) != 2 {
+			return step, errors.New("Expected a duration")
+		}
+
+		duration, err := time.ParseDuration(fields[1])
+		if err != nil {
+			return step, err
+		}
+
+		step.kind = macroStepKindWait
+		step.duration = duration
+	case macroStepKindText:
+		text, err := strconv.Unquote(strings.TrimSpace(source[len(fields[0]):]))
+		if err != nil {
+			return step, errors.New("Expected quoted text")
+		}
+
+		step.kind = macroStepKindText
+		step.text = text
+	case macroStepKindWaitForApp:
+		if len(fields) < 2 || len(fields) > 3 {
+			return step, errors.New("Expected an app ID and optional timeout")
+		}
+
+		step.kind = macroStepKindWaitForApp
+		step.appID = fields[1]
+		step.duration = defaultMacroAppTimeout
+		if len(fields) == 3 {
+			duration, err := time.ParseDuration(fields[2])
+			if err != nil {
+				return step, err
+			}
+			step.duration = duration
+		}
+	default:
+		if len(fields) != 1 {
+			return step, errors.New("Unknown step")
+		}
+
+		name, count := fields[0], 1
+		if i := strings.Index(name, "*"); i >= 0 {
+			var err error
+			count, err = strconv.Atoi(name[i+1:])
+			if err != nil || count < 1 || count > macroMaxKeyRepeat {
+				return step, fmt.Errorf("Key repeat must be between 1 and %v", macroMaxKeyRepeat)
+			}
+			name = name[:i]
+		}
+
+		key, err := ParseKey(name)
+		if err != nil {
+			return step, err
+		}
+
+		step.kind = macroStepKindKey
+		step.key = key
+		step.count = count
+	}
+
+	return step, nil
+}
+
+// waitForApp polls the TV until the app with the provided ID is in the foreground
+func waitForApp(ctx context.Context, tv MacroTarget, appID string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		app, err := tv.GetForegroundApp()
+		if err != nil {
+			return err
+		}
+
+		if app.AppID == appID {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return ErrMacroAppTimeout
+		}
+
+		err = sleepContext(ctx, macroAppPollInterval)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// sleepContext waits for the provided duration, or until the context is done
+func sleepContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,195 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseMacro(t *testing.T) {
+	tests := []struct {
+		name  string
+		macro string
+		want  []macroStep
+	}{
+		{
+			name:  "separators",
+			macro: "HOME, UP; DOWN\nENTER",
+			want: []macroStep{
+				{kind: macroStepKindKey, key: KeyHome, count: 1},
+				{kind: macroStepKindKey, key: KeyUp, count: 1},
+				{kind: macroStepKindKey, key: KeyDown, count: 1},
+				{kind: macroStepKindKey, key: KeyEnter, count: 1},
+			},
+		},
+		{
+			name:  "empty steps",
+			macro: " , HOME,, ;\n",
+			want: []macroStep{
+				{kind: macroStepKindKey, key: KeyHome, count: 1},
+			},
+		},
+		{
+			name:  "lower case key",
+			macro: "home",
+			want: []macroStep{
+				{kind: macroStepKindKey, key: KeyHome, count: 1},
+			},
+		},
+		{
+			name:  "key repeat",
+			macro: "DOWN*3",
+			want: []macroStep{
+				{kind: macroStepKindKey, key: KeyDown, count: 3},
+			},
+		},
+		{
+			name:  "maximum key repeat",
+			macro: "DOWN*100",
+			want: []macroStep{
+				{kind: macroStepKindKey, key: KeyDown, count: 100},
+			},
+		},
+		{
+			name:  "wait",
+			macro: "wait 500ms",
+			want: []macroStep{
+				{kind: macroStepKindWait, duration: 500 * time.Millisecond},
+			},
+		},
+		{
+			name:  "separators inside quotes",
+			macro: "text \"a, b; c\\nd\", ENTER",
+			want: []macroStep{
+				{kind: macroStepKindText, text: "a, b; c\nd"},
+				{kind: macroStepKindKey, key: KeyEnter, count: 1},
+			},
+		},
+		{
+			name:  "escaped quotes",
+			macro: `text "say \"hi\", then go", ENTER`,
+			want: []macroStep{
+				{kind: macroStepKindText, text: `say "hi", then go`},
+				{kind: macroStepKindKey, key: KeyEnter, count: 1},
+			},
+		},
+		{
+			name:  "escaped backslash before a separator",
+			macro: `text "back\\; slash", ENTER`,
+			want: []macroStep{
+				{kind: macroStepKindText, text: `back\; slash`},
+				{kind: macroStepKindKey, key: KeyEnter, count: 1},
+			},
+		},
+		{
+			name:  "wait for app",
+			macro: "app netflix",
+			want: []macroStep{
+				{kind: macroStepKindWaitForApp, appID: "netflix", duration: defaultMacroAppTimeout},
+			},
+		},
+		{
+			name:  "wait for app with timeout",
+			macro: "app netflix 20s",
+			want: []macroStep{
+				{kind: macroStepKindWaitForApp, appID: "netflix", duration: 20 * time.Second},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			macro, err := ParseMacro(tt.macro)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			got := make([]macroStep, len(macro.steps))
+			for i, v := range macro.steps {
+				v.source = ""
+				got[i] = v
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected steps %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseMacroErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		macro string
+	}{
+		{"unknown key", "HOME, NOTAKEY"},
+		{"unknown step", "press HOME"},
+		{"zero key repeat", "DOWN*0"},
+		{"negative key repeat", "DOWN*-1"},
+		{"key repeat over maximum", "DOWN*101"},
+		{"key repeat without count", "DOWN*"},
+		{"key repeat which isn't a number", "DOWN*x"},
+		{"wait without duration", "wait"},
+		{"wait with invalid duration", "wait soon"},
+		{"unterminated quote", `text "abc, ENTER`},
+		{"unterminated escaped quote", `text "abc\", ENTER`},
+		{"unquoted text", "text abc"},
+		{"text after quotes", `text "abc"def`},
+		{"app without ID", "app"},
+		{"app with invalid timeout", "app netflix soon"},
+		{"app with extra fields", "app netflix 10s later"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseMacro(tt.macro)
+			if !errors.Is(err, ErrInvalidMacro) {
+				t.Errorf("Expected ErrInvalidMacro, got %v", err)
+			}
+		})
+	}
+}
+
+func TestMacroRun(t *testing.T) {
+	tv := NewFakeTV()
+	tv.CurrentApp = "netflix"
+
+	macro, err := ParseMacro(`HOME, DOWN*2, text "a, b", app netflix`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	macro.KeyDelay = 0
+
+	if err := macro.Run(context.Background(), tv); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	wantKeys := []Key{KeyHome, KeyDown, KeyDown}
+	if !reflect.DeepEqual(tv.PressedKeys, wantKeys) {
+		t.Errorf("Expected keys %v, got %v", wantKeys, tv.PressedKeys)
+	}
+	if tv.Text != "a, b" {
+		t.Errorf("Expected text %q, got %q", "a, b", tv.Text)
+	}
+}
+
+func TestMacroRunError(t *testing.T) {
+	tv := NewFakeTV()
+	tv.Errors["PressKeys"] = errors.New("Key press failed")
+
+	macro, err := ParseMacro("wait 0s, HOME")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = macro.Run(context.Background(), tv)
+
+	var macroErr *MacroError
+	if !errors.As(err, &macroErr) {
+		t.Fatalf("Expected a MacroError, got %v", err)
+	}
+	if macroErr.Index != 1 || macroErr.Step != "HOME" || macroErr.Err != tv.Errors["PressKeys"] {
+		t.Errorf("Expected step 1 (HOME) to fail, got %+v", macroErr)
+	}
+}
@@ -0,0 +1,343 @@
+package control
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dhickie/go-lgtv/connection"
+)
+
+const (
+	alertSettingsCategory = "other"
+	alertSettingsKey      = "goLgtvAlertButton"
+	// alertClosed is sent instead of a button index when the alert is closed on the TV
+	alertClosed = -1
+	// alertCloseGrace is how long to wait for a button's marker after the alert is closed on the
+	// TV, in case the marker arrives late
+	alertCloseGrace = 500 * time.Millisecond
+)
+
+// ErrNoAlertButtons is returned when trying to show an alert without any buttons
+var ErrNoAlertButtons = errors.New("An alert must have at least one button")
+
+// ToastOptions are the options used when showing a toast notification on the TV
+type ToastOptions struct {
+	// IconPath is the path of a local image file to show as the toast's icon
+	IconPath string
+	// IconData is the content of an image to show as the toast's icon, if IconPath isn't set
+	IconData []byte
+	// IconExtension is the file extension of IconData, such as "png"
+	IconExtension string
+	// OnClick is the action taken when the toast is clicked, if any
+	OnClick *ToastAction
+}
+
+// ToastAction is an action taken when a toast notification is clicked
+type ToastAction struct {
+	// AppID is the ID of the app to launch
+	AppID string
+	// Params are the parameters to launch the app with, if any
+	Params interface{}
+}
+
+// ShowToast shows a toast notification with the provided message on the TV, using the provided
+// options, which can be nil. It returns the ID of the new toast.
+func (tv *LgTv) ShowToast(message string, opts *ToastOptions) (string, error) {
+	payload := connection.CreateToastPayload{
+		Message: message,
+	}
+
+	if opts != nil {
+		iconData, iconExtension := opts.IconData, opts.IconExtension
+		if opts.IconPath != "" {
+			data, err := os.ReadFile(opts.IconPath)
+			if err != nil {
+				return "", err
+			}
+
+			iconData = data
+			iconExtension = filepath.Ext(opts.IconPath)
+		}
+
+		if len(iconData) > 0 {
+			payload.IconData = base64.StdEncoding.EncodeToString(iconData)
+			payload.IconExtension = strings.ToLower(strings.TrimPrefix(iconExtension, "."))
+		}
+
+		if opts.OnClick != nil {
+			payload.OnClick = &connection.ToastAction{
+				AppID:  opts.OnClick.AppID,
+				Params: opts.OnClick.Params,
+			}
+		}
+	}
+
+	var respPayload connection.CreateToastResponsePayload
+	err := tv.doRequest(uriCreateToast, payload, &respPayload)
+	if err != nil {
+		return "", err
+	}
+
+	return respPayload.ToastID, nil
+}
+
+// AlertButton is a button shown on an alert
+type AlertButton struct {
+	// Label is the text shown on the button
+	Label string
+	// OnPress is called when the button is pressed, if set
+	OnPress func()
+}
+
+// AlertOptions are the options used when showing an alert on the TV
+type AlertOptions struct {
+	// Timeout is how long the alert is shown for before it's closed automatically. If it's
+	// zero, the alert is shown until a button is pressed or it's closed.
+	Timeout time.Duration
+}
+
+// Alert is a modal alert with buttons shown on the TV
+type Alert struct {
+	// ID is the ID the TV gave the alert
+	ID string
+
+	ctx        context.Context
+	cancel     context.CancelFunc
+	closeAlert func() error
+	done       chan struct{}
+	closeOnce  sync.Once
+	pressed    int
+}
+
+// newAlert returns a new alert, which calls closeAlert to close it on the TV
+func newAlert(closeAlert func() error) *Alert {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Alert{
+		ctx:        ctx,
+		cancel:     cancel,
+		closeAlert: closeAlert,
+		done:       make(chan struct{}),
+		pressed:    -1,
+	}
+}
+
+// ShowAlert shows a modal alert with the provided message and buttons on the TV, using the provided
+// options, which can be nil. When a button is pressed, its OnPress callback is called and the alert
+// is closed.
+//
+// The TV doesn't report which button was pressed on an alert, and an alert's actions can only call
+// services on the TV. To find out, each button writes a marker to the "goLgtvAlertButton" key in
+// the "other" category of the TV's system settings when pressed, which the client watches for. The
+// alert deletes the key again when it closes, so nothing is left behind in the TV's settings. If the
+// key is deleted without a marker having been written, the alert was closed on the TV without a
+// button being pressed, for example using BACK or EXIT.
+func (tv *LgTv) ShowAlert(message string, buttons []AlertButton, opts *AlertOptions) (*Alert, error) {
+	if len(buttons) == 0 {
+		return nil, ErrNoAlertButtons
+	}
+
+	token, err := newAlertToken()
+	if err != nil {
+		return nil, err
+	}
+
+	alert := newAlert(nil)
+	alert.closeAlert = func() error {
+		return tv.doRequest(uriCloseAlert, connection.CloseAlertPayload{
+			AlertID: alert.ID,
+		}, nil)
+	}
+
+	// Start watching for button presses before the alert is shown, so none are missed
+	presses := make(chan int, 1)
+	subPayload := connection.GetSystemSettingsPayload{
+		Category: alertSettingsCategory,
+		Keys:     []string{alertSettingsKey},
+	}
+	err = tv.subscribe(alert.ctx, uriGetSystemSettings, subPayload,
+		func() interface{} {
+			return &connection.GetSystemSettingsResponsePayload{}
+		},
+		alertMarkerHandler(alert.ctx, token, len(buttons), presses),
+		func() {
+			close(presses)
+		})
+	if err != nil {
+		alert.cancel()
+		return nil, err
+	}
+
+	// Show the alert, with each button storing its own marker when pressed, and the marker
+	// being deleted once the alert closes
+	payload := connection.CreateAlertPayload{
+		Message: message,
+		Buttons: make([]connection.AlertButton, len(buttons)),
+		OnClose: &connection.AlertAction{
+			URI: uriLunaDeleteSystemSettings,
+			Params: connection.DeleteSystemSettingsPayload{
+				Category: alertSettingsCategory,
+				Keys:     []string{alertSettingsKey},
+			},
+		},
+	}
+	for i, v := range buttons {
+		payload.Buttons[i] = connection.AlertButton{
+			Label:   v.Label,
+			OnClick: uriLunaSetSystemSettings,
+			Params: connection.SetSystemSettingsPayload{
+				Category: alertSettingsCategory,
+				Settings: map[string]interface{}{
+					alertSettingsKey: fmt.Sprintf("%v:%v", token, i),
+				},
+			},
+		}
+	}
+
+	var respPayload connection.CreateAlertResponsePayload
+	err = tv.doRequest(uriCreateAlert, payload, &respPayload)
+	if err != nil {
+		alert.cancel()
+		return nil, err
+	}
+	alert.ID = respPayload.AlertID
+
+	var timeout time.Duration
+	if opts != nil {
+		timeout = opts.Timeout
+	}
+
+	go alert.watch(presses, buttons, timeout)
+
+	return alert, nil
+}
+
+// Close closes the alert on the TV, if it hasn't been closed already
+func (a *Alert) Close() error {
+	var err error
+	a.closeOnce.Do(func() {
+		a.cancel()
+		err = a.closeAlert()
+	})
+
+	return err
+}
+
+// Done returns a channel which is closed once the alert has been closed, either because a
+// button was pressed, it was closed on the TV, it timed out, or Close was called
+func (a *Alert) Done() <-chan struct{} {
+	return a.done
+}
+
+// Wait waits for the alert to be closed, and returns the index of the button which was pressed,
+// or -1 if it was closed without a button being pressed
+func (a *Alert) Wait() int {
+	<-a.done
+	return a.pressed
+}
+
+func (a *Alert) watch(presses <-chan int, buttons []AlertButton, timeout time.Duration) {
+	defer close(a.done)
+
+	var timedOut <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timedOut = timer.C
+	}
+
+	var closedOnTV <-chan time.Time
+	for {
+		select {
+		case index, ok := <-presses:
+			if !ok {
+				// Watching stopped because the alert was closed, or the connection was lost
+				return
+			}
+
+			if index == alertClosed {
+				// Give a button's marker a chance to arrive before deciding none was pressed
+				if closedOnTV == nil {
+					closedOnTV = time.After(alertCloseGrace)
+				}
+				continue
+			}
+
+			// The TV closes the alert itself when a button is pressed
+			a.closeOnce.Do(a.cancel)
+			a.pressed = index
+			if buttons[index].OnPress != nil {
+				buttons[index].OnPress()
+			}
+			return
+		case <-closedOnTV:
+			a.closeOnce.Do(a.cancel)
+			return
+		case <-timedOut:
+			a.Close()
+			return
+		case <-a.ctx.Done():
+			// The alert was closed
+			return
+		}
+	}
+}
+
+// alertMarkerHandler returns a handler for changes to the alert marker in the TV's settings, which
+// sends the index of the button pressed, or alertClosed if the marker was deleted without a button
+// being pressed
+func alertMarkerHandler(ctx context.Context, token string, buttons int, presses chan<- int) func(payload interface{}) bool {
+	initial := true
+	return func(payload interface{}) bool {
+		value, _ := payload.(*connection.GetSystemSettingsResponsePayload).Settings[alertSettingsKey].(string)
+
+		// The first value is whatever was there before the alert was shown
+		first := initial
+		initial = false
+
+		if value == "" && !first {
+			// The alert deleted the marker when it closed. Carry on watching, in case a button's
+			// marker is yet to arrive.
+			select {
+			case presses <- alertClosed:
+			default:
+			}
+			return true
+		}
+
+		if !strings.HasPrefix(value, token+":") {
+			// Either the marker from an earlier alert, or something else changed
+			return true
+		}
+
+		var index int
+		_, err := fmt.Sscanf(strings.TrimPrefix(value, token+":"), "%d", &index)
+		if err != nil || index < 0 || index >= buttons {
+			return true
+		}
+
+		select {
+		case presses <- index:
+		case <-ctx.Done():
+		}
+		return false
+	}
+}
+
+func newAlertToken() (string, error) {
+	b := make([]byte, 8)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
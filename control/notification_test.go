@@ -0,0 +1,158 @@
+package control
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dhickie/go-lgtv/connection"
+)
+
+func TestAlertMarkerHandler(t *testing.T) {
+	const token = "0123456789abcdef"
+
+	tests := []struct {
+		name      string
+		values    []string
+		want      []int
+		wantStops bool
+	}{
+		{"button pressed", []string{"", token + ":1"}, []int{1}, true},
+		{"closed without a press", []string{"", ""}, []int{alertClosed}, false},
+		{"closed and then a late press", []string{"", "", token + ":0"}, []int{alertClosed, 0}, true},
+		{"earlier alert's marker", []string{"fedcba9876543210:0"}, nil, false},
+		{"marker from another alert", []string{"", "fedcba9876543210:0"}, nil, false},
+		{"button out of range", []string{"", token + ":2"}, nil, false},
+		{"initial value deleted", []string{"fedcba9876543210:0", ""}, []int{alertClosed}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			presses := make(chan int, len(tt.values))
+			handler := alertMarkerHandler(context.Background(), token, 2, presses)
+
+			stopped := false
+			for _, v := range tt.values {
+				payload := &connection.GetSystemSettingsResponsePayload{
+					Settings: map[string]interface{}{},
+				}
+				if v != "" {
+					payload.Settings[alertSettingsKey] = v
+				}
+
+				if !handler(payload) {
+					stopped = true
+					break
+				}
+			}
+			close(presses)
+
+			var got []int
+			for v := range presses {
+				got = append(got, v)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Expected %v, got %v", tt.want, got)
+				}
+			}
+			if stopped != tt.wantStops {
+				t.Errorf("Expected stopped to be %v, got %v", tt.wantStops, stopped)
+			}
+		})
+	}
+}
+
+func TestAlertWait(t *testing.T) {
+	tests := []struct {
+		name    string
+		act     func(tv *FakeTV, alert *Alert) error
+		timeout time.Duration
+		want    int
+		closed  bool
+	}{
+		{
+			name: "button pressed",
+			act: func(tv *FakeTV, alert *Alert) error {
+				return tv.PressAlertButton(1)
+			},
+			want: 1,
+		},
+		{
+			name: "dismissed on the TV",
+			act: func(tv *FakeTV, alert *Alert) error {
+				return tv.DismissAlert()
+			},
+			want: alertClosed,
+		},
+		{
+			name: "closed by the client",
+			act: func(tv *FakeTV, alert *Alert) error {
+				return alert.Close()
+			},
+			want:   alertClosed,
+			closed: true,
+		},
+		{
+			name: "timed out",
+			act: func(tv *FakeTV, alert *Alert) error {
+				return nil
+			},
+			timeout: 10 * time.Millisecond,
+			want:    alertClosed,
+			closed:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tv := NewFakeTV()
+
+			pressed := -1
+			buttons := []AlertButton{
+				{Label: "No", OnPress: func() { pressed = 0 }},
+				{Label: "Yes", OnPress: func() { pressed = 1 }},
+			}
+			alert, err := tv.ShowAlert("Continue?", buttons, &AlertOptions{Timeout: tt.timeout})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := tt.act(tv, alert); err != nil {
+				t.Fatal(err)
+			}
+
+			select {
+			case <-alert.Done():
+			case <-time.After(5 * time.Second):
+				t.Fatal("Timed out waiting for the alert to close")
+			}
+
+			if got := alert.Wait(); got != tt.want {
+				t.Errorf("Expected button %v, got %v", tt.want, got)
+			}
+			if pressed != tt.want {
+				t.Errorf("Expected OnPress for button %v, got %v", tt.want, pressed)
+			}
+			if closed := len(tv.CallsTo("CloseAlert")) == 1; closed != tt.closed {
+				t.Errorf("Expected the alert to be closed by the client to be %v, got %v", tt.closed, closed)
+			}
+		})
+	}
+}
+
+func TestAlertLatePress(t *testing.T) {
+	alert := newAlert(func() error { return nil })
+	presses := make(chan int, 2)
+	presses <- alertClosed
+	presses <- 0
+
+	go alert.watch(presses, []AlertButton{{Label: "OK"}}, 0)
+
+	if got := alert.Wait(); got != 0 {
+		t.Errorf("Expected button 0, got %v", got)
+	}
+}
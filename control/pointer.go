@@ -0,0 +1,286 @@
+package control
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/dhickie/go-lgtv/connection"
+)
+
+const defaultPointerInterval = 20 * time.Millisecond
+
+// PointerOptions are the options used to control how pointer input is sent to the TV
+type PointerOptions struct {
+	// Interval is the minimum time between pointer movements being sent to the TV. Movements
+	// made in between are batched together. Defaults to 20ms.
+	Interval time.Duration
+	// Smoothing is how much movement is spread out over subsequent intervals, between 0 (no
+	// smoothing, everything is sent at once) and 1. For example, with a smoothing of 0.5, half
+	// of the outstanding movement is sent in each interval.
+	Smoothing float64
+}
+
+// Pointer controls the TV's pointer, in the same way as the Magic Remote.
+//
+// Movements and scrolls aren't sent to the TV straight away. Instead, they're added up and
+// sent at most once per interval, so that a fast input source like a touch surface doesn't
+// flood the TV. Clicks are sent straight away, after any outstanding movement.
+type Pointer struct {
+	tv        *LgTv
+	interval  time.Duration
+	smoothing float64
+	// sendLock is held while sending to the TV, so that movement is sent in order
+	sendLock sync.Mutex
+	// lock guards the outstanding movement, and is never held while sending to the TV
+	lock     sync.Mutex
+	moveX    float64
+	moveY    float64
+	dragX    float64
+	dragY    float64
+	scrollX  float64
+	scrollY  float64
+	err      error
+	wake     chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// pointerMovement is movement taken from a pointer to be sent to the TV
+type pointerMovement struct {
+	moveX, moveY     int
+	dragX, dragY     int
+	scrollX, scrollY int
+}
+
+// Pointer returns a new pointer for the TV using the provided options, which can be nil to use the
+// defaults. The pointer must be closed once it's no longer needed.
+func (tv *LgTv) Pointer(opts *PointerOptions) *Pointer {
+	p := &Pointer{
+		tv:       tv,
+		interval: defaultPointerInterval,
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+	}
+
+	if opts != nil {
+		if opts.Interval > 0 {
+			p.interval = opts.Interval
+		}
+		p.smoothing = math.Max(0, math.Min(opts.Smoothing, 0.95))
+	}
+
+	go p.worker()
+
+	return p
+}
+
+// Move moves the pointer by the provided number of pixels
+func (p *Pointer) Move(dx, dy float64) {
+	p.lock.Lock()
+	p.moveX += dx
+	p.moveY += dy
+	p.lock.Unlock()
+
+	p.notify()
+}
+
+// Drag moves the pointer by the provided number of pixels with the button held down. Drags are
+// kept separate from movements made with Move, and within an interval are sent after them.
+func (p *Pointer) Drag(dx, dy float64) {
+	p.lock.Lock()
+	p.dragX += dx
+	p.dragY += dy
+	p.lock.Unlock()
+
+	p.notify()
+}
+
+// Scroll scrolls by the provided amount
+func (p *Pointer) Scroll(dx, dy float64) {
+	p.lock.Lock()
+	p.scrollX += dx
+	p.scrollY += dy
+	p.lock.Unlock()
+
+	p.notify()
+}
+
+// Click clicks at the pointer's position, once any outstanding movement has been sent
+func (p *Pointer) Click() error {
+	p.sendLock.Lock()
+	defer p.sendLock.Unlock()
+
+	err := p.take(true).send(p.tv)
+	if err != nil {
+		return err
+	}
+
+	return p.tv.withPointerSocket(func(socket *connection.PointerSocket) error {
+		return socket.SendClick()
+	})
+}
+
+// Err returns the last error which occurred sending movement to the TV in the background,
+// and clears it
+func (p *Pointer) Err() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	err := p.err
+	p.err = nil
+	return err
+}
+
+// Close sends any outstanding movement to the TV, and stops the pointer
+func (p *Pointer) Close() error {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+
+	p.sendLock.Lock()
+	defer p.sendLock.Unlock()
+
+	return p.take(true).send(p.tv)
+}
+
+func (p *Pointer) notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (p *Pointer) worker() {
+	for {
+		// Wait until there's something to send
+		select {
+		case <-p.stop:
+			return
+		case <-p.wake:
+		}
+
+		// Send movement once per interval until there's nothing left
+		ticker := time.NewTicker(p.interval)
+		for pending := true; pending; {
+			select {
+			case <-p.stop:
+				ticker.Stop()
+				return
+			case <-ticker.C:
+				pending = p.flush()
+			}
+		}
+		ticker.Stop()
+	}
+}
+
+// flush sends the movement for the current interval to the TV, and returns whether there is
+// still movement left to send
+func (p *Pointer) flush() bool {
+	p.sendLock.Lock()
+	defer p.sendLock.Unlock()
+
+	movement := p.take(false)
+	if movement.empty() {
+		return false
+	}
+
+	err := movement.send(p.tv)
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if err != nil {
+		// Drop the movement rather than retrying it forever
+		p.err = err
+		p.moveX, p.moveY, p.dragX, p.dragY, p.scrollX, p.scrollY = 0, 0, 0, 0, 0, 0
+		return false
+	}
+
+	return p.hasPending()
+}
+
+// take takes the outstanding movement to send to the TV. Unless all is true, only part of the
+// movement is taken, based on the pointer's smoothing. Fractions of a pixel are left behind for
+// the next interval.
+func (p *Pointer) take(all bool) pointerMovement {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	share := 1 - p.smoothing
+	if all {
+		share = 1
+	}
+
+	return pointerMovement{
+		moveX:   takeWhole(&p.moveX, share),
+		moveY:   takeWhole(&p.moveY, share),
+		dragX:   takeWhole(&p.dragX, share),
+		dragY:   takeWhole(&p.dragY, share),
+		scrollX: takeWhole(&p.scrollX, 1),
+		scrollY: takeWhole(&p.scrollY, 1),
+	}
+}
+
+// hasPending returns whether there is at least a whole pixel of movement left to send.
+// The lock must be held by the caller.
+func (p *Pointer) hasPending() bool {
+	for _, v := range []float64{p.moveX, p.moveY, p.dragX, p.dragY, p.scrollX, p.scrollY} {
+		if math.Abs(v) >= 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m pointerMovement) empty() bool {
+	return m == pointerMovement{}
+}
+
+// send sends the movement to the TV, with movements before drags. Each message is sent
+// separately, so that if the socket has to be reopened, only the message which failed is
+// sent again.
+func (m pointerMovement) send(tv *LgTv) error {
+	var frames []func(socket *connection.PointerSocket) error
+	if m.moveX != 0 || m.moveY != 0 {
+		frames = append(frames, func(socket *connection.PointerSocket) error {
+			return socket.SendMove(m.moveX, m.moveY, false)
+		})
+	}
+
+	if m.dragX != 0 || m.dragY != 0 {
+		frames = append(frames, func(socket *connection.PointerSocket) error {
+			return socket.SendMove(m.dragX, m.dragY, true)
+		})
+	}
+
+	if m.scrollX != 0 || m.scrollY != 0 {
+		frames = append(frames, func(socket *connection.PointerSocket) error {
+			return socket.SendScroll(m.scrollX, m.scrollY)
+		})
+	}
+
+	for _, v := range frames {
+		err := tv.withPointerSocket(v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// takeWhole takes the provided share of the value, rounded towards zero to a whole number, and
+// subtracts it from the value. If less than a whole pixel would be taken, but there's at least a
+// whole pixel left, one pixel is taken so that smoothed movement always finishes.
+func takeWhole(value *float64, share float64) int {
+	taken := math.Trunc(*value * share)
+	if taken == 0 && math.Abs(*value) >= 1 {
+		taken = math.Copysign(1, *value)
+	}
+
+	*value -= taken
+	return int(taken)
+}
@@ -0,0 +1,96 @@
+package control
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dhickie/go-lgtv/connection"
+)
+
+// PowerState is the power state of the TV
+type PowerState string
+
+// Power states of the TV
+const (
+	// PowerActive is when the TV is on, with the screen on
+	PowerActive PowerState = "Active"
+	// PowerScreenOff is when the TV is on, but the screen has been turned off, for example to listen to music
+	PowerScreenOff PowerState = "Screen Off"
+	// PowerActiveStandby is when the TV looks like it's off, but is still running in the background,
+	// for example to record a program or download an update
+	PowerActiveStandby PowerState = "Active Standby"
+	// PowerSuspend is when the TV is off
+	PowerSuspend PowerState = "Suspend"
+)
+
+// PowerStatus is the power state of the TV, along with any change to it which is in progress
+type PowerStatus struct {
+	State PowerState
+	// Processing describes a change to the power state which is in progress, for example
+	// "Request Active Standby". It's empty if nothing is changing.
+	Processing string
+}
+
+// IsOn returns whether the TV is on, with or without the screen on, and isn't in the process of
+// turning off
+func (s PowerStatus) IsOn() bool {
+	return (s.State == PowerActive || s.State == PowerScreenOff) && !s.TurningOff()
+}
+
+// TurningOff returns whether the TV is on its way to standby or being turned off
+func (s PowerStatus) TurningOff() bool {
+	processing := strings.ToLower(s.Processing)
+	for _, v := range []string{"standby", "suspend", "power off"} {
+		if strings.Contains(processing, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetPowerState returns the power state of the TV
+func (tv *LgTv) GetPowerState() (PowerStatus, error) {
+	var respPayload connection.GetPowerStateResponsePayload
+	err := tv.doRequest(uriGetPowerState, nil, &respPayload)
+	if err != nil {
+		return PowerStatus{}, err
+	}
+
+	return convertPowerState(respPayload), nil
+}
+
+// SubscribePowerState subscribes to changes to the power state of the TV. The current state is sent
+// straight away, then again each time it changes. The returned channel is closed once the context is
+// done, or the connection to the TV is lost, which happens when the TV is suspended.
+func (tv *LgTv) SubscribePowerState(ctx context.Context) (<-chan PowerStatus, error) {
+	states := make(chan PowerStatus)
+	err := tv.subscribe(ctx, uriGetPowerState, nil,
+		func() interface{} {
+			return &connection.GetPowerStateResponsePayload{}
+		},
+		func(payload interface{}) bool {
+			state := convertPowerState(*payload.(*connection.GetPowerStateResponsePayload))
+			select {
+			case states <- state:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		},
+		func() {
+			close(states)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return states, nil
+}
+
+func convertPowerState(payload connection.GetPowerStateResponsePayload) PowerStatus {
+	return PowerStatus{
+		State:      PowerState(payload.State),
+		Processing: payload.Processing,
+	}
+}
@@ -0,0 +1,273 @@
+package control
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrQueueClosed is returned for commands which are added to a command queue after it has been
+// closed, or which were still waiting to be sent when it was closed
+var ErrQueueClosed = errors.New("Command queue is closed")
+
+const (
+	cmdVolumeStep = iota
+	cmdSetVolume
+	cmdChannelUp
+	cmdChannelDown
+	cmdSetChannel
+	cmdFunc
+)
+
+// Future holds the outcome of a command added to a CommandQueue, which is available once the
+// command has been sent to the TV
+type Future struct {
+	done chan struct{}
+	err  error
+}
+
+// Wait blocks until the command has been sent to the TV, and returns its outcome
+func (f *Future) Wait() error {
+	<-f.done
+	return f.err
+}
+
+// Done returns a channel which is closed once the command has been sent to the TV
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Err returns the outcome of the command, or nil if it hasn't been sent to the TV yet
+func (f *Future) Err() error {
+	select {
+	case <-f.done:
+		return f.err
+	default:
+		return nil
+	}
+}
+
+func (f *Future) resolve(err error) {
+	f.err = err
+	close(f.done)
+}
+
+// A command waiting in the queue. Several commands added to the queue can be coalesced
+// in to one, in which case the futures of all of them are resolved with its outcome.
+type command struct {
+	kind    int
+	value   int
+	f       func() error
+	futures []*Future
+}
+
+// CommandQueue sends commands to a TV at a limited rate. Commands are sent in the order
+// they're added, and no more often than the queue's interval.
+//
+// While a command is waiting to be sent, idempotent commands added after it are coalesced
+// in to it. Consecutive volume changes are combined in to a single change (so ten VolumeUps
+// become one SetVolume to 10 above the current volume), and consecutive SetChannels only
+// keep the last channel.
+type CommandQueue struct {
+	tv       Controller
+	interval time.Duration
+	lock     sync.Mutex
+	pending  []*command
+	closed   bool
+	wake     chan struct{}
+	stop     chan struct{}
+}
+
+// NewCommandQueue returns a new command queue which sends commands to the provided TV,
+// waiting at least the provided interval between each one
+func NewCommandQueue(tv Controller, interval time.Duration) *CommandQueue {
+	q := &CommandQueue{
+		tv:       tv,
+		interval: interval,
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+	}
+
+	go q.worker()
+
+	return q
+}
+
+// VolumeUp queues a command to increase the volume by 1
+func (q *CommandQueue) VolumeUp() *Future {
+	return q.add(&command{kind: cmdVolumeStep, value: 1})
+}
+
+// VolumeDown queues a command to decrease the volume by 1
+func (q *CommandQueue) VolumeDown() *Future {
+	return q.add(&command{kind: cmdVolumeStep, value: -1})
+}
+
+// SetVolume queues a command to set the volume to the specified value
+func (q *CommandQueue) SetVolume(value int) *Future {
+	return q.add(&command{kind: cmdSetVolume, value: value})
+}
+
+// ChannelUp queues a command to change the current channel up by 1
+func (q *CommandQueue) ChannelUp() *Future {
+	return q.add(&command{kind: cmdChannelUp})
+}
+
+// ChannelDown queues a command to change the current channel down by 1
+func (q *CommandQueue) ChannelDown() *Future {
+	return q.add(&command{kind: cmdChannelDown})
+}
+
+// SetChannel queues a command to set the current channel to the specified number
+func (q *CommandQueue) SetChannel(channelNumber int) *Future {
+	return q.add(&command{kind: cmdSetChannel, value: channelNumber})
+}
+
+// Do queues an arbitrary function to be run against the TV. It's never coalesced with
+// any other command.
+func (q *CommandQueue) Do(f func() error) *Future {
+	return q.add(&command{kind: cmdFunc, f: f})
+}
+
+// Close stops the queue. Any commands still waiting to be sent are resolved with ErrQueueClosed.
+func (q *CommandQueue) Close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.closed {
+		return
+	}
+
+	q.closed = true
+	close(q.stop)
+	for _, v := range q.pending {
+		resolveAll(v.futures, ErrQueueClosed)
+	}
+	q.pending = nil
+}
+
+func (q *CommandQueue) add(cmd *command) *Future {
+	future := &Future{
+		done: make(chan struct{}),
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.closed {
+		future.resolve(ErrQueueClosed)
+		return future
+	}
+
+	// Try to coalesce the command with the last one waiting to be sent. Only the last
+	// one is considered so that commands are never reordered.
+	if len(q.pending) > 0 && coalesce(q.pending[len(q.pending)-1], cmd) {
+		last := q.pending[len(q.pending)-1]
+		last.futures = append(last.futures, future)
+		return future
+	}
+
+	cmd.futures = []*Future{future}
+	q.pending = append(q.pending, cmd)
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+	return future
+}
+
+func (q *CommandQueue) next() *command {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if len(q.pending) == 0 {
+		return nil
+	}
+
+	cmd := q.pending[0]
+	q.pending = q.pending[1:]
+	return cmd
+}
+
+func (q *CommandQueue) worker() {
+	for {
+		cmd := q.next()
+		if cmd == nil {
+			select {
+			case <-q.stop:
+				return
+			case <-q.wake:
+				continue
+			}
+		}
+
+		resolveAll(cmd.futures, q.run(cmd))
+
+		// Wait for the interval before sending the next command
+		timer := time.NewTimer(q.interval)
+		select {
+		case <-q.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (q *CommandQueue) run(cmd *command) error {
+	switch cmd.kind {
+	case cmdVolumeStep:
+		switch cmd.value {
+		case 0:
+			return nil
+		case 1:
+			return q.tv.VolumeUp()
+		case -1:
+			return q.tv.VolumeDown()
+		}
+
+		volume, err := q.tv.GetVolume()
+		if err != nil {
+			return err
+		}
+		return q.tv.SetVolume(clampVolume(volume + cmd.value))
+	case cmdSetVolume:
+		return q.tv.SetVolume(clampVolume(cmd.value))
+	case cmdChannelUp:
+		return q.tv.ChannelUp()
+	case cmdChannelDown:
+		return q.tv.ChannelDown()
+	case cmdSetChannel:
+		return q.tv.SetChannel(cmd.value)
+	default:
+		return cmd.f()
+	}
+}
+
+// coalesce merges the next command in to the last one if it's possible to do so, and
+// returns whether it did
+func coalesce(last, next *command) bool {
+	switch {
+	case last.kind == cmdVolumeStep && next.kind == cmdVolumeStep:
+		last.value += next.value
+	case last.kind == cmdSetVolume && next.kind == cmdVolumeStep:
+		last.value += next.value
+	case (last.kind == cmdVolumeStep || last.kind == cmdSetVolume) && next.kind == cmdSetVolume:
+		last.kind = cmdSetVolume
+		last.value = next.value
+	case last.kind == cmdSetChannel && next.kind == cmdSetChannel:
+		last.value = next.value
+	default:
+		return false
+	}
+
+	return true
+}
+
+func resolveAll(futures []*Future, err error) {
+	for _, v := range futures {
+		v.resolve(err)
+	}
+}
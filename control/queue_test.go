@@ -0,0 +1,122 @@
+package control
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCommandQueueCoalescing(t *testing.T) {
+	tests := []struct {
+		name   string
+		volume int
+		add    func(q *CommandQueue) []*Future
+		want   []FakeCall
+	}{
+		{
+			name:   "ten volume ups",
+			volume: 5,
+			add: func(q *CommandQueue) []*Future {
+				var futures []*Future
+				for i := 0; i < 10; i++ {
+					futures = append(futures, q.VolumeUp())
+				}
+				return futures
+			},
+			want: []FakeCall{{"GetVolume", nil}, {"SetVolume", []interface{}{15}}},
+		},
+		{
+			name:   "single volume up",
+			volume: 5,
+			add: func(q *CommandQueue) []*Future {
+				return []*Future{q.VolumeUp()}
+			},
+			want: []FakeCall{{"VolumeUp", nil}},
+		},
+		{
+			name:   "volume steps which cancel out",
+			volume: 5,
+			add: func(q *CommandQueue) []*Future {
+				return []*Future{q.VolumeUp(), q.VolumeDown()}
+			},
+			want: []FakeCall{},
+		},
+		{
+			name:   "set volume followed by steps",
+			volume: 5,
+			add: func(q *CommandQueue) []*Future {
+				return []*Future{q.SetVolume(20), q.VolumeUp(), q.VolumeUp(), q.VolumeDown()}
+			},
+			want: []FakeCall{{"SetVolume", []interface{}{21}}},
+		},
+		{
+			name:   "steps followed by set volume",
+			volume: 5,
+			add: func(q *CommandQueue) []*Future {
+				return []*Future{q.VolumeUp(), q.VolumeUp(), q.SetVolume(7)}
+			},
+			want: []FakeCall{{"SetVolume", []interface{}{7}}},
+		},
+		{
+			name:   "set volume followed by steps past the maximum",
+			volume: 5,
+			add: func(q *CommandQueue) []*Future {
+				return []*Future{q.SetVolume(99), q.VolumeUp(), q.VolumeUp(), q.VolumeUp()}
+			},
+			want: []FakeCall{{"SetVolume", []interface{}{100}}},
+		},
+		{
+			name: "last set channel wins",
+			add: func(q *CommandQueue) []*Future {
+				return []*Future{q.SetChannel(1), q.SetChannel(2), q.SetChannel(3)}
+			},
+			want: []FakeCall{{"SetChannel", []interface{}{3}}},
+		},
+		{
+			name: "set channels separated by another command",
+			add: func(q *CommandQueue) []*Future {
+				return []*Future{q.SetChannel(1), q.ChannelUp(), q.SetChannel(2)}
+			},
+			want: []FakeCall{{"SetChannel", []interface{}{1}}, {"ChannelUp", nil}, {"SetChannel", []interface{}{2}}},
+		},
+		{
+			name:   "volume steps separated by another command",
+			volume: 5,
+			add: func(q *CommandQueue) []*Future {
+				return []*Future{q.VolumeUp(), q.ChannelUp(), q.VolumeUp()}
+			},
+			want: []FakeCall{{"VolumeUp", nil}, {"ChannelUp", nil}, {"VolumeUp", nil}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tv := NewFakeTV()
+			tv.Volume = tt.volume
+
+			q := NewCommandQueue(tv, time.Millisecond)
+			defer q.Close()
+
+			// Hold the queue up until all of the commands have been added, so that they're all
+			// waiting to be sent together
+			release := make(chan struct{})
+			q.Do(func() error {
+				<-release
+				return nil
+			})
+
+			futures := tt.add(q)
+			close(release)
+
+			for _, v := range futures {
+				if err := v.Wait(); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+			}
+
+			if got := tv.Calls(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected calls %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
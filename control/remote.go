@@ -0,0 +1,168 @@
+package control
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/dhickie/go-lgtv/connection"
+)
+
+// ErrUnknownKey is returned when parsing the name of a button which isn't on the TV's remote control
+var ErrUnknownKey = errors.New("Unknown remote control key")
+
+// Key is a button on the TV's remote control
+type Key string
+
+// Buttons on the TV's remote control
+const (
+	KeyHome        Key = "HOME"
+	KeyBack        Key = "BACK"
+	KeyExit        Key = "EXIT"
+	KeyUp          Key = "UP"
+	KeyDown        Key = "DOWN"
+	KeyLeft        Key = "LEFT"
+	KeyRight       Key = "RIGHT"
+	KeyEnter       Key = "ENTER"
+	KeyMenu        Key = "MENU"
+	KeyQuickMenu   Key = "QMENU"
+	KeyInfo        Key = "INFO"
+	KeyGuide       Key = "GUIDE"
+	KeyList        Key = "LIST"
+	KeyMyApps      Key = "MYAPPS"
+	KeyRecent      Key = "RECENT"
+	Key0           Key = "0"
+	Key1           Key = "1"
+	Key2           Key = "2"
+	Key3           Key = "3"
+	Key4           Key = "4"
+	Key5           Key = "5"
+	Key6           Key = "6"
+	Key7           Key = "7"
+	Key8           Key = "8"
+	Key9           Key = "9"
+	KeyDash        Key = "DASH"
+	KeyAsterisk    Key = "ASTERISK"
+	KeyRed         Key = "RED"
+	KeyGreen       Key = "GREEN"
+	KeyYellow      Key = "YELLOW"
+	KeyBlue        Key = "BLUE"
+	KeyVolumeUp    Key = "VOLUMEUP"
+	KeyVolumeDown  Key = "VOLUMEDOWN"
+	KeyMute        Key = "MUTE"
+	KeyChannelUp   Key = "CHANNELUP"
+	KeyChannelDown Key = "CHANNELDOWN"
+	KeyPlay        Key = "PLAY"
+	KeyPause       Key = "PAUSE"
+	KeyStop        Key = "STOP"
+	KeyRewind      Key = "REWIND"
+	KeyFastForward Key = "FASTFORWARD"
+	KeyRecord      Key = "RECORD"
+	KeySubtitles   Key = "CC"
+	KeyAudioDesc   Key = "AD"
+	KeySAP         Key = "SAP"
+	Key3DMode      Key = "3D_MODE"
+)
+
+var allKeys = []Key{
+	KeyHome, KeyBack, KeyExit, KeyUp, KeyDown, KeyLeft, KeyRight, KeyEnter, KeyMenu, KeyQuickMenu,
+	KeyInfo, KeyGuide, KeyList, KeyMyApps, KeyRecent, Key0, Key1, Key2, Key3, Key4, Key5, Key6,
+	Key7, Key8, Key9, KeyDash, KeyAsterisk, KeyRed, KeyGreen, KeyYellow, KeyBlue, KeyVolumeUp,
+	KeyVolumeDown, KeyMute, KeyChannelUp, KeyChannelDown, KeyPlay, KeyPause, KeyStop, KeyRewind,
+	KeyFastForward, KeyRecord, KeySubtitles, KeyAudioDesc, KeySAP, Key3DMode,
+}
+
+// ParseKey returns the remote control key with the provided name, ignoring case
+func ParseKey(name string) (Key, error) {
+	for _, v := range allKeys {
+		if strings.EqualFold(string(v), name) {
+			return v, nil
+		}
+	}
+
+	return "", ErrUnknownKey
+}
+
+// Remote sends remote control button presses to the TV
+type Remote struct {
+	tv *LgTv
+}
+
+// Remote returns a remote control for the TV. Button presses are sent over the TV's pointer
+// input socket, which is opened the first time a button is pressed.
+func (tv *LgTv) Remote() *Remote {
+	return &Remote{
+		tv: tv,
+	}
+}
+
+// Press presses each of the provided buttons on the remote, in order
+func (r *Remote) Press(keys ...Key) error {
+	for _, v := range keys {
+		err := r.tv.withPointerSocket(func(socket *connection.PointerSocket) error {
+			return socket.SendButton(string(v))
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PressKeys presses each of the provided buttons on the TV's remote control, in order
+func (tv *LgTv) PressKeys(keys ...Key) error {
+	return tv.Remote().Press(keys...)
+}
+
+// pointerSocketHolder holds the pointer input socket for a TV, which is opened when it's first needed
+type pointerSocketHolder struct {
+	lock   sync.Mutex
+	socket *connection.PointerSocket
+}
+
+// withPointerSocket calls f with the TV's pointer input socket, opening it first if needed.
+// If sending over an existing socket fails, it's assumed to have been closed by the TV, so
+// it's reopened and f is tried once more. Since f may be called twice, it must only send a
+// single message.
+func (tv *LgTv) withPointerSocket(f func(socket *connection.PointerSocket) error) error {
+	tv.pointer.lock.Lock()
+	defer tv.pointer.lock.Unlock()
+
+	reused := tv.pointer.socket != nil
+	for {
+		if tv.pointer.socket == nil {
+			var respPayload connection.GetPointerInputSocketResponsePayload
+			err := tv.doRequest(uriGetPointerInputSocket, nil, &respPayload)
+			if err != nil {
+				return err
+			}
+
+			socket, err := connection.NewPointerSocket(respPayload.SocketPath, tv.timeout)
+			if err != nil {
+				return err
+			}
+			tv.pointer.socket = socket
+		}
+
+		err := f(tv.pointer.socket)
+		if err == nil || !reused {
+			return err
+		}
+
+		tv.pointer.socket.Close()
+		tv.pointer.socket = nil
+		reused = false
+	}
+}
+
+// closePointerSocket closes the TV's pointer input socket if it's open
+func (tv *LgTv) closePointerSocket() {
+	tv.pointer.lock.Lock()
+	defer tv.pointer.lock.Unlock()
+
+	if tv.pointer.socket != nil {
+		tv.pointer.socket.Close()
+		tv.pointer.socket = nil
+	}
+}
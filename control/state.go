@@ -0,0 +1,98 @@
+package control
+
+import "github.com/dhickie/go-lgtv/connection"
+
+// ConnectionState is the state of the connection between the client and the TV
+type ConnectionState int
+
+const (
+	// StateDisconnected means there is no connection to the TV
+	StateDisconnected ConnectionState = iota
+	// StateConnecting means a connection to the TV is being opened
+	StateConnecting
+	// StateAwaitingPairing means the TV is prompting the user to accept the connection, either when
+	// connecting or reconnecting. Calling Disconnect cancels it.
+	StateAwaitingPairing
+	// StateConnected means the client is connected and registered with the TV
+	StateConnected
+	// StateReconnecting means the connection to the TV was lost, and it's being reopened
+	StateReconnecting
+	// StateClosed means the connection to the TV was closed using Disconnect
+	StateClosed
+)
+
+// StateChangeHandler is called when the connection state of a TV changes
+type StateChangeHandler func(oldState, newState ConnectionState)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case StateDisconnected:
+		return "Disconnected"
+	case StateConnecting:
+		return "Connecting"
+	case StateAwaitingPairing:
+		return "AwaitingPairing"
+	case StateConnected:
+		return "Connected"
+	case StateReconnecting:
+		return "Reconnecting"
+	case StateClosed:
+		return "Closed"
+	default:
+		return "Unknown"
+	}
+}
+
+// State returns the current state of the connection to the TV
+func (tv *LgTv) State() ConnectionState {
+	tv.stateLock.Lock()
+	defer tv.stateLock.Unlock()
+
+	return tv.state
+}
+
+// IsConnected returns whether the client is currently connected to the TV
+func (tv *LgTv) IsConnected() bool {
+	return tv.State() == StateConnected
+}
+
+// OnStateChange registers a handler to be called whenever the connection state of the TV
+// changes. Handlers are called in the order state changes happen, from the goroutine which
+// caused the change, so they must not call Connect or Disconnect themselves.
+func (tv *LgTv) OnStateChange(handler StateChangeHandler) {
+	tv.stateLock.Lock()
+	defer tv.stateLock.Unlock()
+
+	tv.stateHandlers = append(tv.stateHandlers, handler)
+}
+
+func (tv *LgTv) setState(state ConnectionState) {
+	tv.notifyLock.Lock()
+	defer tv.notifyLock.Unlock()
+
+	tv.stateLock.Lock()
+	oldState := tv.state
+	tv.state = state
+	handlers := tv.stateHandlers
+	tv.stateLock.Unlock()
+
+	if oldState == state {
+		return
+	}
+
+	for _, v := range handlers {
+		v(oldState, state)
+	}
+}
+
+// currentConn returns the connection to the TV if connected, or nil if not
+func (tv *LgTv) currentConn() *connection.Connection {
+	tv.stateLock.Lock()
+	defer tv.stateLock.Unlock()
+
+	if tv.state != StateConnected {
+		return nil
+	}
+
+	return tv.conn
+}
@@ -0,0 +1,59 @@
+package control
+
+import (
+	"context"
+
+	"github.com/dhickie/go-lgtv/connection"
+)
+
+// subscribe subscribes to changes on the TV at the provided URI. Each message from the TV is
+// unmarshalled in to a new payload from newPayload, and passed to deliver. This continues until
+// deliver returns false, the context is done, or the connection to the TV is lost, at which
+// point the subscription is closed and finish is called.
+func (tv *LgTv) subscribe(ctx context.Context, uri string, reqPayload interface{},
+	newPayload func() interface{}, deliver func(payload interface{}) bool, finish func()) error {
+	conn := tv.currentConn()
+	if conn == nil {
+		return ErrNotConnected
+	}
+	if !tv.supports(uri) {
+		return ErrNotSupported
+	}
+
+	sub, err := conn.Subscribe(uri, reqPayload)
+	if err != nil {
+		return tv.checkSupported(uri, err)
+	}
+
+	go func() {
+		defer finish()
+		defer sub.Close()
+
+		// Close the subscription as soon as the context is done, so that waiting
+		// for the next message is interrupted
+		go func() {
+			select {
+			case <-ctx.Done():
+				sub.Close()
+			case <-sub.Done():
+			}
+		}()
+
+		for {
+			payload := newPayload()
+			err := sub.Next(payload)
+			if err == connection.ErrSubscriptionClosed {
+				return
+			} else if err != nil {
+				// Skip messages which can't be understood
+				continue
+			}
+
+			if !deliver(payload) {
+				return
+			}
+		}
+	}()
+
+	return nil
+}
@@ -18,18 +18,36 @@ import (
 // ErrNotConnected is returned if an request is attempted to a TV which is not connected to the client
 var ErrNotConnected = errors.New("Client is not connected to TV")
 
+// ErrConnectCancelled is returned when Disconnect is called while connecting to the TV
+var ErrConnectCancelled = errors.New("Connecting to the TV was cancelled")
+
 // ErrInsufficientNetworkDetails is returned if an attempt is made to turn on a tv without providing a mac address and subnet mask
 var ErrInsufficientNetworkDetails = errors.New("Insufficient network information was supplied to use this function")
 
+const (
+	reconnectAttempts     = 5
+	reconnectDelaySeconds = 1
+)
+
 // LgTv represents the TV being controlled
 type LgTv struct {
 	ip            net.IP
 	mac           string
 	broadcastAddr net.IP
 	conn          *connection.Connection
+	pendingConn   *connection.Connection
+	opening       bool
+	openCancelled bool
 	connLock      *sync.Mutex
+	timeout       int
+	stateLock     sync.Mutex
+	notifyLock    sync.Mutex
+	state         ConnectionState
+	stateHandlers []StateChangeHandler
+	pointer       pointerSocketHolder
+	iconCacheDir  string
+	capabilities  Capabilities
 	ClientKey     string
-	IsConnected   bool
 }
 
 // NewTV returns a new LgTv object with the specified IP address
@@ -55,41 +73,73 @@ func NewTV(ip, macAddress, subnet string) (*LgTv, error) {
 		broadcastAddr: broadcastAddress,
 		conn:          nil,
 		connLock:      new(sync.Mutex),
-		IsConnected:   false,
+		state:         StateDisconnected,
 	}, err
 }
 
 // Connect connects to the tv using the provided client key. If an empty client key
 // is provided, a new one will be provisioned
+//
+// If the connection to the TV is lost after connecting, the client will try to reconnect
+// using the same client key. OnStateChange can be used to follow the state of the connection.
+// Calling Disconnect before the connection has been made, including while waiting for it to be
+// accepted on the TV, cancels it, in which case ErrConnectCancelled is returned.
 func (tv *LgTv) Connect(clientKey string, timeout int) (string, error) {
 	// Only one thread should be allowed to try and connect at the same time
-	if !tv.IsConnected {
-		tv.connLock.Lock()
-		defer tv.connLock.Unlock()
-		if !tv.IsConnected {
-			conn, err := connection.NewConnection(tv.ip, timeout)
-			if err != nil {
-				return "", err
-			}
+	tv.connLock.Lock()
+	defer tv.connLock.Unlock()
 
-			tv.conn = conn
-			clientKey, err = tv.conn.Register(clientKey)
-			if err == nil {
-				tv.IsConnected = true
-				tv.ClientKey = clientKey
-			}
+	if tv.State() == StateConnected {
+		return tv.ClientKey, nil
+	}
 
-			return clientKey, err
-		}
+	tv.setState(StateConnecting)
+	conn, clientKey, err := tv.open(clientKey, timeout, func() {
+		tv.setState(StateAwaitingPairing)
+	})
+	if err == ErrConnectCancelled {
+		// Disconnect moves the TV to the closed state once it has the connection lock
+		return "", err
+	} else if err != nil {
+		tv.setState(StateDisconnected)
+		return "", err
 	}
 
-	return tv.ClientKey, nil
+	tv.timeout = timeout
+	tv.ClientKey = clientKey
+	tv.setConnected(conn)
+
+	return clientKey, nil
 }
 
 // Disconnect disconnects from the TV
 func (tv *LgTv) Disconnect() error {
-	tv.IsConnected = false
-	return tv.conn.Close()
+	// Cancel any connection which is still being set up first, without waiting for the connection
+	// lock, so that waiting for the connection to be accepted on the TV is cancelled
+	tv.stateLock.Lock()
+	tv.openCancelled = tv.opening
+	pending := tv.pendingConn
+	tv.pendingConn = nil
+	tv.stateLock.Unlock()
+	if pending != nil {
+		pending.Close()
+	}
+
+	tv.connLock.Lock()
+	defer tv.connLock.Unlock()
+
+	tv.stateLock.Lock()
+	conn := tv.conn
+	tv.conn = nil
+	tv.stateLock.Unlock()
+
+	tv.setState(StateClosed)
+	tv.closePointerSocket()
+	if conn == nil {
+		return nil
+	}
+
+	return conn.Close()
 }
 
 // VolumeUp increases the volume by 1
@@ -187,23 +237,7 @@ func (tv *LgTv) ListChannels() ([]Channel, error) {
 		return nil, err
 	}
 
-	channels := make([]Channel, len(respPayload.ChannelList))
-	for i, v := range respPayload.ChannelList {
-		channelNum, err := strconv.Atoi(v.ChannelNumber)
-		if err != nil {
-			return nil, err
-		}
-
-		channels[i] = Channel{
-			ChannelName:   v.ChannelName,
-			ChannelNumber: channelNum,
-			IsHdtv:        v.HDTV,
-			IsScrambled:   v.Scrambled,
-			tv:            tv,
-		}
-	}
-
-	return channels, nil
+	return tv.convertChannelList(respPayload)
 }
 
 // GetCurrentChannel returns the channel the TV is currently set to
@@ -214,18 +248,7 @@ func (tv *LgTv) GetCurrentChannel() (Channel, error) {
 		return Channel{}, err
 	}
 
-	channelNum, err := strconv.Atoi(respPayload.ChannelNumber)
-	if err != nil {
-		return Channel{}, err
-	}
-
-	return Channel{
-		ChannelName:   respPayload.ChannelName,
-		ChannelNumber: channelNum,
-		IsHdtv:        false,
-		IsScrambled:   respPayload.IsScrambled,
-		tv:            tv,
-	}, nil
+	return tv.convertCurrentChannel(respPayload)
 }
 
 // GetChannelProgramList gets the list of programs broadcast on the current channel
@@ -297,33 +320,63 @@ func (tv *LgTv) ListExternalInputs() ([]Input, error) {
 		return nil, err
 	}
 
-	inputs := make([]Input, len(respPayload.Devices))
-	for i, v := range respPayload.Devices {
-		inputs[i] = Input{
-			ID:    v.ID,
-			Label: v.Label,
-			tv:    tv,
-		}
-	}
-
-	return inputs, nil
+	return tv.convertInputList(respPayload), nil
 }
 
-// ListInstalledApps lists the apps currently installed on the TV
-func (tv *LgTv) ListInstalledApps() ([]App, error) {
+// ListInstalledApps lists the apps currently installed on the TV. If any filters are provided, only
+// the apps which are included by all of them are returned, for example:
+//
+//	apps, err := tv.ListInstalledApps(control.VisibleOnly, control.ExcludeSystemApps)
+func (tv *LgTv) ListInstalledApps(filters ...AppFilter) ([]App, error) {
 	var respPayload connection.GetInstalledAppsResponsePayload
 	err := tv.doRequest(uriListApps, nil, &respPayload)
 	if err != nil {
 		return nil, err
 	}
 
-	apps := make([]App, len(respPayload.Apps))
-	for i, v := range respPayload.Apps {
-		apps[i] = App{
-			Name: v.Title,
-			ID:   v.ID,
-			tv:   tv,
+	return filterApps(tv.convertAppList(respPayload), filters), nil
+}
+
+// ListRunningApps lists the apps currently running on the TV, including whether each one is
+// visible or running in the background
+func (tv *LgTv) ListRunningApps() ([]App, error) {
+	// The list of running apps only has their IDs, so the installed apps and the foreground
+	// app are needed too to fill in the rest
+	var runningPayload connection.GetRunningAppsResponsePayload
+	var installedPayload connection.GetInstalledAppsResponsePayload
+	var foregroundPayload connection.GetForegroundAppInfoResponsePayload
+	errs := tv.doBatch([]connection.BatchRequest{
+		{URI: uriGetRunningApps, Response: &runningPayload},
+		{URI: uriListApps, Response: &installedPayload},
+		{URI: uriGetForegroundAppInfo, Response: &foregroundPayload},
+	})
+	for _, v := range errs {
+		if v != nil {
+			return nil, v
+		}
+	}
+
+	installed := make(map[string]App)
+	for _, v := range tv.convertAppList(installedPayload) {
+		installed[v.ID] = v
+	}
+
+	apps := make([]App, len(runningPayload.Running))
+	for i, v := range runningPayload.Running {
+		app, ok := installed[v.ID]
+		if !ok {
+			app = App{
+				Name: v.ID,
+				ID:   v.ID,
+				tv:   tv,
+			}
 		}
+
+		app.State = AppState{
+			Running: true,
+			Visible: v.ID == foregroundPayload.AppID,
+		}
+		apps[i] = app
 	}
 
 	return apps, nil
@@ -332,8 +385,18 @@ func (tv *LgTv) ListInstalledApps() ([]App, error) {
 // LaunchApp launches the app with the provided ID. If successfully launched,
 // it returns the ID of the new session
 func (tv *LgTv) LaunchApp(appID string) (string, error) {
+	return tv.LaunchAppWithParams(appID, "", nil)
+}
+
+// LaunchAppWithParams launches the app with the provided ID, passing it the provided content ID
+// and parameters, either of which can be empty. What these mean depends on the app, but they're
+// generally used to open a particular piece of content. If successfully launched, it returns
+// the ID of the new session
+func (tv *LgTv) LaunchAppWithParams(appID, contentID string, params interface{}) (string, error) {
 	payload := connection.LaunchAppPayload{
-		ID: appID,
+		ID:         appID,
+		ContentID:  contentID,
+		Parameters: params,
 	}
 	var respPayload connection.LaunchAppResponsePayload
 	err := tv.doRequest(uriLaunchApp, payload, &respPayload)
@@ -344,6 +407,54 @@ func (tv *LgTv) LaunchApp(appID string) (string, error) {
 	return respPayload.SessionID, nil
 }
 
+// CloseApp closes the app with the provided ID
+func (tv *LgTv) CloseApp(appID string) error {
+	payload := connection.CloseAppPayload{
+		ID: appID,
+	}
+	return tv.doRequest(uriCloseApp, payload, nil)
+}
+
+// CloseSession closes the app session with the provided ID, as returned when launching an app
+func (tv *LgTv) CloseSession(sessionID string) error {
+	payload := connection.CloseAppPayload{
+		SessionID: sessionID,
+	}
+	return tv.doRequest(uriCloseApp, payload, nil)
+}
+
+// GetAppState returns whether the app with the provided ID is running, and whether it's visible
+func (tv *LgTv) GetAppState(appID string) (AppState, error) {
+	payload := connection.GetAppStatePayload{
+		ID: appID,
+	}
+	var respPayload connection.GetAppStateResponsePayload
+	err := tv.doRequest(uriGetAppState, payload, &respPayload)
+	if err != nil {
+		return AppState{}, err
+	}
+
+	return AppState{
+		Running: respPayload.Running,
+		Visible: respPayload.Visible,
+	}, nil
+}
+
+// OpenURL opens the provided URL in the TV's web browser. If successfully opened, it returns
+// the ID of the new session
+func (tv *LgTv) OpenURL(url string) (string, error) {
+	payload := connection.OpenPayload{
+		Target: url,
+	}
+	var respPayload connection.LaunchAppResponsePayload
+	err := tv.doRequest(uriOpen, payload, &respPayload)
+	if err != nil {
+		return "", err
+	}
+
+	return respPayload.SessionID, nil
+}
+
 // TurnOff turns the tv off
 func (tv *LgTv) TurnOff() error {
 	return tv.doRequest(uriTurnOff, nil, nil)
@@ -361,12 +472,245 @@ func (tv *LgTv) TurnOn() error {
 	return ErrInsufficientNetworkDetails
 }
 
+// open opens a new connection to the TV and registers with it. If Disconnect is called before
+// it's finished, ErrConnectCancelled is returned. The connection lock must be held by the caller.
+func (tv *LgTv) open(clientKey string, timeout int, onPrompt func()) (*connection.Connection, string, error) {
+	tv.stateLock.Lock()
+	tv.opening = true
+	tv.openCancelled = false
+	tv.stateLock.Unlock()
+
+	conn, err := connection.NewConnection(tv.ip, timeout)
+	if err != nil {
+		if tv.finishOpen() {
+			err = ErrConnectCancelled
+		}
+		return nil, "", err
+	}
+
+	// Disconnect may have been called while dialing, before there was a connection for it to close
+	tv.stateLock.Lock()
+	cancelled := tv.openCancelled
+	if !cancelled {
+		tv.pendingConn = conn
+	}
+	tv.stateLock.Unlock()
+
+	if !cancelled {
+		clientKey, err = conn.RegisterWithPrompt(clientKey, onPrompt)
+	}
+
+	if tv.finishOpen() {
+		err = ErrConnectCancelled
+	}
+	if err != nil {
+		conn.Close()
+		return nil, "", err
+	}
+
+	return conn, clientKey, nil
+}
+
+// finishOpen records that opening a connection has finished, and returns whether it was cancelled
+func (tv *LgTv) finishOpen() bool {
+	tv.stateLock.Lock()
+	defer tv.stateLock.Unlock()
+
+	cancelled := tv.openCancelled
+	tv.opening = false
+	tv.openCancelled = false
+	tv.pendingConn = nil
+	return cancelled
+}
+
+// setConnected makes the provided connection the TV's current connection, and starts
+// watching it in case it's lost. The connection lock must be held by the caller.
+func (tv *LgTv) setConnected(conn *connection.Connection) {
+	capabilities := loadCapabilities(conn, tv.Capabilities())
+
+	tv.stateLock.Lock()
+	tv.conn = conn
+	tv.capabilities = capabilities
+	tv.stateLock.Unlock()
+
+	tv.setState(StateConnected)
+	go tv.watchConnection(conn)
+}
+
+// watchConnection waits for the provided connection to be lost, and tries to reconnect
+// to the TV if it is
+func (tv *LgTv) watchConnection(conn *connection.Connection) {
+	<-conn.Done()
+
+	tv.connLock.Lock()
+	defer tv.connLock.Unlock()
+
+	// If the connection was closed deliberately, or has already been replaced, there's nothing to do
+	tv.stateLock.Lock()
+	current := tv.conn == conn && tv.state == StateConnected
+	if current {
+		tv.conn = nil
+	}
+	tv.stateLock.Unlock()
+
+	if !current {
+		return
+	}
+
+	tv.closePointerSocket()
+	tv.setState(StateReconnecting)
+	delay := reconnectDelaySeconds * time.Second
+	for i := 0; i < reconnectAttempts; i++ {
+		// Give up the lock while waiting, so that the client can still disconnect
+		tv.connLock.Unlock()
+		time.Sleep(delay)
+		tv.connLock.Lock()
+
+		if tv.State() != StateReconnecting {
+			return
+		}
+
+		// If the client key is no longer accepted, the TV asks for the connection to be accepted again
+		newConn, _, err := tv.open(tv.ClientKey, tv.timeout, func() {
+			tv.setState(StateAwaitingPairing)
+		})
+		if err == nil {
+			tv.setConnected(newConn)
+			return
+		} else if err == ErrConnectCancelled {
+			return
+		}
+		tv.setState(StateReconnecting)
+
+		delay *= 2
+	}
+
+	tv.setState(StateDisconnected)
+}
+
 func (tv *LgTv) doRequest(uri string, reqPayload interface{}, respPayload interface{}) error {
-	if tv.IsConnected {
-		return tv.conn.Request(uri, reqPayload, respPayload)
+	conn := tv.currentConn()
+	if conn == nil {
+		return ErrNotConnected
+	}
+	if !tv.supports(uri) {
+		return ErrNotSupported
+	}
+
+	err := conn.Request(uri, reqPayload, respPayload)
+	return tv.checkSupported(uri, err)
+}
+
+func (tv *LgTv) doBatch(requests []connection.BatchRequest) []error {
+	errs := make([]error, len(requests))
+	conn := tv.currentConn()
+	if conn == nil {
+		for i := range errs {
+			errs[i] = ErrNotConnected
+		}
+		return errs
+	}
+
+	// Only send the requests which the TV supports
+	var supported []connection.BatchRequest
+	var indexes []int
+	for i, v := range requests {
+		if tv.supports(v.URI) {
+			supported = append(supported, v)
+			indexes = append(indexes, i)
+		} else {
+			errs[i] = ErrNotSupported
+		}
+	}
+
+	if len(supported) > 0 {
+		for i, v := range conn.RequestBatch(supported) {
+			errs[indexes[i]] = tv.checkSupported(supported[i].URI, v)
+		}
+	}
+
+	return errs
+}
+
+func (tv *LgTv) convertChannelList(payload connection.GetChannelListResponsePayload) ([]Channel, error) {
+	channels := make([]Channel, len(payload.ChannelList))
+	for i, v := range payload.ChannelList {
+		channelNum, err := strconv.Atoi(v.ChannelNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		channels[i] = Channel{
+			ChannelName:   v.ChannelName,
+			ChannelNumber: channelNum,
+			IsHdtv:        v.HDTV,
+			IsScrambled:   v.Scrambled,
+			tv:            tv,
+		}
+	}
+
+	return channels, nil
+}
+
+func (tv *LgTv) convertCurrentChannel(payload connection.GetCurrentChannelResponsePayload) (Channel, error) {
+	channelNum, err := strconv.Atoi(payload.ChannelNumber)
+	if err != nil {
+		return Channel{}, err
+	}
+
+	return Channel{
+		ChannelName:   payload.ChannelName,
+		ChannelNumber: channelNum,
+		IsHdtv:        false,
+		IsScrambled:   payload.IsScrambled,
+		tv:            tv,
+	}, nil
+}
+
+func (tv *LgTv) convertInputList(payload connection.GetExternalInputListResponsePayload) []Input {
+	inputs := make([]Input, len(payload.Devices))
+	for i, v := range payload.Devices {
+		inputs[i] = Input{
+			ID:      v.ID,
+			Label:   v.Label,
+			iconURL: v.Icon,
+			tv:      tv,
+		}
 	}
 
-	return ErrNotConnected
+	return inputs
+}
+
+func (tv *LgTv) convertAppList(payload connection.GetInstalledAppsResponsePayload) []App {
+	apps := make([]App, len(payload.Apps))
+	for i, v := range payload.Apps {
+		iconURL := v.Icon
+		if iconURL == "" {
+			iconURL = v.LargeIcon
+		}
+
+		var installedTime time.Time
+		if v.InstalledTime > 0 {
+			installedTime = time.Unix(int64(v.InstalledTime), 0)
+		}
+
+		apps[i] = App{
+			Name:          v.Title,
+			ID:            v.ID,
+			Version:       v.Version,
+			Vendor:        v.Vendor,
+			Category:      v.Category,
+			SystemApp:     v.SystemApp,
+			Visible:       v.Visible,
+			Removable:     v.Removable,
+			InstalledTime: installedTime,
+			Size:          int64(v.AppSize),
+			iconURL:       iconURL,
+			tv:            tv,
+		}
+	}
+
+	return apps
 }
 
 func parseTime(strTime string) (time.Time, error) {
@@ -407,3 +751,14 @@ func calculateBroadcastAddress(ip, subnet net.IP) net.IP {
 
 	return broadcastAddress
 }
+
+func clampVolume(volume int) int {
+	if volume < 0 {
+		return 0
+	}
+	if volume > 100 {
+		return 100
+	}
+
+	return volume
+}
@@ -0,0 +1,197 @@
+package control
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeTVServer listens on the TV's websocket port, and asks for every registration to be accepted
+// on the TV without ever accepting it
+type fakeTVServer struct {
+	// beforeUpgrade is called before each websocket connection is accepted, if set
+	beforeUpgrade func()
+}
+
+func (s *fakeTVServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.beforeUpgrade != nil {
+		s.beforeUpgrade()
+	}
+
+	upgrader := websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req struct {
+			ID   int    `json:"id"`
+			Type string `json:"type"`
+		}
+		if json.Unmarshal(message, &req) != nil || req.Type != "register" {
+			continue
+		}
+
+		conn.WriteJSON(map[string]interface{}{
+			"id":   req.ID,
+			"type": "response",
+			"payload": map[string]interface{}{
+				"pairingType": "PROMPT",
+				"returnValue": true,
+			},
+		})
+	}
+}
+
+// startFakeTVServer starts the server, skipping the test if the TV's port isn't available
+func startFakeTVServer(t *testing.T, server *fakeTVServer) {
+	listener, err := net.Listen("tcp", "127.0.0.1:3000")
+	if err != nil {
+		t.Skipf("TV port isn't available: %v", err)
+	}
+
+	httpServer := &http.Server{Handler: server}
+	go httpServer.Serve(listener)
+	t.Cleanup(func() {
+		httpServer.Close()
+	})
+}
+
+// recordStates returns a function which returns the states the TV has moved to so far
+func recordStates(tv *LgTv, changed chan<- ConnectionState) func() []ConnectionState {
+	var lock sync.Mutex
+	var states []ConnectionState
+	tv.OnStateChange(func(oldState, newState ConnectionState) {
+		lock.Lock()
+		states = append(states, newState)
+		lock.Unlock()
+
+		select {
+		case changed <- newState:
+		default:
+		}
+	})
+
+	return func() []ConnectionState {
+		lock.Lock()
+		defer lock.Unlock()
+
+		return append([]ConnectionState(nil), states...)
+	}
+}
+
+func TestDisconnectWhileAwaitingPairing(t *testing.T) {
+	startFakeTVServer(t, &fakeTVServer{})
+
+	tv, err := NewTV("127.0.0.1", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed := make(chan ConnectionState, 10)
+	states := recordStates(tv, changed)
+
+	connectErr := make(chan error, 1)
+	go func() {
+		_, err := tv.Connect("", 1000)
+		connectErr <- err
+	}()
+
+	for state := range changed {
+		if state == StateAwaitingPairing {
+			break
+		}
+	}
+
+	if err := tv.Disconnect(); err != nil {
+		t.Fatalf("Expected no error disconnecting, got %v", err)
+	}
+
+	select {
+	case err := <-connectErr:
+		if err != ErrConnectCancelled {
+			t.Errorf("Expected ErrConnectCancelled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for Connect to return")
+	}
+
+	want := []ConnectionState{StateConnecting, StateAwaitingPairing, StateClosed}
+	if got := states(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected states %v, got %v", want, got)
+	}
+}
+
+func TestDisconnectWhileDialing(t *testing.T) {
+	dialing := make(chan struct{})
+	release := make(chan struct{})
+	startFakeTVServer(t, &fakeTVServer{
+		beforeUpgrade: func() {
+			close(dialing)
+			<-release
+		},
+	})
+
+	tv, err := NewTV("127.0.0.1", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed := make(chan ConnectionState, 10)
+	states := recordStates(tv, changed)
+
+	connectErr := make(chan error, 1)
+	go func() {
+		_, err := tv.Connect("", 5000)
+		connectErr <- err
+	}()
+
+	<-dialing
+
+	// Disconnect waits for Connect to finish, so it can only be called in the background
+	disconnectErr := make(chan error, 1)
+	go func() {
+		disconnectErr <- tv.Disconnect()
+	}()
+
+	// Only let the dial finish once Disconnect has cancelled it
+	for cancelled := false; !cancelled; {
+		time.Sleep(time.Millisecond)
+
+		tv.stateLock.Lock()
+		cancelled = tv.openCancelled
+		tv.stateLock.Unlock()
+	}
+	close(release)
+
+	select {
+	case err := <-connectErr:
+		if err != ErrConnectCancelled {
+			t.Errorf("Expected ErrConnectCancelled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for Connect to return")
+	}
+
+	if err := <-disconnectErr; err != nil {
+		t.Fatalf("Expected no error disconnecting, got %v", err)
+	}
+
+	want := []ConnectionState{StateConnecting, StateClosed}
+	if got := states(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected states %v, got %v", want, got)
+	}
+}
@@ -1,6 +1,8 @@
 package control
 
 const (
+	uriGetServiceList = "ssap://api/getServiceList"
+
 	uriVolumeUp   = "ssap://audio/volumeUp"
 	uriVolumeDown = "ssap://audio/volumeDown"
 	uriSetVolume  = "ssap://audio/setVolume"
@@ -23,9 +25,33 @@ const (
 	uriGetCurrentChannel     = "ssap://tv/getCurrentChannel"
 	uriGetChannelProgramInfo = "ssap://tv/getChannelProgramInfo"
 
-	uriListApps = "ssap://com.webos.applicationManager/listApps"
+	uriListApps             = "ssap://com.webos.applicationManager/listApps"
+	uriGetForegroundAppInfo = "ssap://com.webos.applicationManager/getForegroundAppInfo"
+	uriGetRunningApps       = "ssap://com.webos.applicationManager/running"
+	uriListLaunchPoints     = "ssap://com.webos.applicationManager/listLaunchPoints"
+
+	uriGetPointerInputSocket = "ssap://com.webos.service.networkinput/getPointerInputSocket"
+
+	uriInsertText       = "ssap://com.webos.service.ime/insertText"
+	uriDeleteCharacters = "ssap://com.webos.service.ime/deleteCharacters"
+	uriSendEnterKey     = "ssap://com.webos.service.ime/sendEnterKey"
+	uriRegisterKeyboard = "ssap://com.webos.service.ime/registerRemoteKeyboard"
+
+	uriLaunchApp   = "ssap://system.launcher/launch"
+	uriOpen        = "ssap://system.launcher/open"
+	uriCloseApp    = "ssap://system.launcher/close"
+	uriGetAppState = "ssap://system.launcher/getAppState"
+
+	uriCreateToast = "ssap://system.notifications/createToast"
+	uriCreateAlert = "ssap://system.notifications/createAlert"
+	uriCloseAlert  = "ssap://system.notifications/closeAlert"
 
-	uriLaunchApp = "ssap://system.launcher/launch"
+	uriGetSystemSettings        = "ssap://settings/getSystemSettings"
+	uriLunaSetSystemSettings    = "luna://com.webos.settingsservice/setSystemSettings"
+	uriLunaDeleteSystemSettings = "luna://com.webos.settingsservice/deleteSystemSettings"
 
-	uriTurnOff = "ssap://system/turnOff"
+	uriTurnOff         = "ssap://system/turnOff"
+	uriGetPowerState   = "ssap://com.webos.service.tvpower/power/getPowerState"
+	uriGetSystemInfo   = "ssap://system/getSystemInfo"
+	uriGetSoftwareInfo = "ssap://com.webos.service.update/getCurrentSWInformation"
 )